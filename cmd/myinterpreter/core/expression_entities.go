@@ -56,8 +56,80 @@ func (v Assign) Accept(visitor ExpressionVisitor) (any, Error) {
 	return visitor.VisitAssignExpr(v)
 }
 
+// CompoundAssign is `name op= value`, e.g. `total += price`.
+type CompoundAssign struct {
+	Name     Token
+	Operator Token
+	Value    Expression
+}
+
+func (c CompoundAssign) Accept(visitor ExpressionVisitor) (any, Error) {
+	return visitor.VisitCompoundAssignExpr(c)
+}
+
+// Increment is `++name`, `--name`, `name++` or `name--`. Prefix forms evaluate
+// to the updated value and postfix forms to the previous one.
+type Increment struct {
+	Name     Token
+	Operator Token
+	Prefix   bool
+}
+
+func (i Increment) Accept(visitor ExpressionVisitor) (any, Error) {
+	return visitor.VisitIncrementExpr(i)
+}
+
+// Conditional is `condition ? then : else`. Only the chosen branch is evaluated.
+type Conditional struct {
+	Question  Token
+	Condition Expression
+	Then      Expression
+	Else      Expression
+}
+
+func (c Conditional) Accept(visitor ExpressionVisitor) (any, Error) {
+	return visitor.VisitConditionalExpr(c)
+}
+
+// Logical is a short-circuiting binary operator such as `??`: the right
+// operand is only evaluated when the left one does not decide the result.
+type Logical struct {
+	Left     Expression
+	Operator Token
+	Right    Expression
+}
+
+func (l Logical) Accept(visitor ExpressionVisitor) (any, Error) {
+	return visitor.VisitLogicalExpr(l)
+}
+
+// Call is `callee(arguments...)`. Paren is the closing parenthesis, whose line
+// is reported for errors raised by the call.
+type Call struct {
+	Callee    Expression
+	Paren     Token
+	Arguments []Expression
+}
+
+func (c Call) Accept(visitor ExpressionVisitor) (any, Error) {
+	return visitor.VisitCallExpr(c)
+}
+
+// Get is a property access, `object.name`.
+type Get struct {
+	Object Expression
+	Name   Token
+}
+
+func (g Get) Accept(visitor ExpressionVisitor) (any, Error) {
+	return visitor.VisitGetExpr(g)
+}
+
 type Error struct {
 	Line     int
 	Err      error
 	ExitCode int
+	// Trace holds the call stack at the point a runtime error was raised,
+	// innermost frame first. It is empty for scan and parse errors.
+	Trace []TraceFrame
 }
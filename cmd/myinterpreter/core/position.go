@@ -0,0 +1,81 @@
+package core
+
+// StatementLine returns the source line a statement begins on, or 0 for
+// blocks and statements made of tokenless expressions such as literals.
+func StatementLine(stmt Statement) int {
+	switch node := stmt.(type) {
+	case ExpressionStmt:
+		return ExpressionLine(node.Expr)
+	case PrintStmt:
+		return node.Keyword.Line
+	case VarStmt:
+		return node.Name.Line
+	case FunctionStmt:
+		return node.Name.Line
+	case ReturnStmt:
+		return node.Keyword.Line
+	case ThrowStmt:
+		return node.Keyword.Line
+	case TryStmt:
+		return node.Keyword.Line
+	case IfStmt:
+		return node.Keyword.Line
+	case WhileStmt:
+		return node.Keyword.Line
+	case ForStmt:
+		return node.Keyword.Line
+	case ImportStmt:
+		return node.Keyword.Line
+	}
+
+	return 0
+}
+
+// ExpressionLine returns the line of the leftmost token of an expression, or
+// 0 when it has none.
+func ExpressionLine(expr Expression) int {
+	switch node := expr.(type) {
+	case Binary:
+		if line := ExpressionLine(node.Left); line > 0 {
+			return line
+		}
+		return node.Operator.Line
+	case Logical:
+		if line := ExpressionLine(node.Left); line > 0 {
+			return line
+		}
+		return node.Operator.Line
+	case Grouping:
+		return ExpressionLine(node.Expr)
+	case Unary:
+		return node.Operator.Line
+	case Variable:
+		return node.Name.Line
+	case Assign:
+		return node.Name.Line
+	case CompoundAssign:
+		return node.Name.Line
+	case Increment:
+		if node.Prefix {
+			return node.Operator.Line
+		}
+		return node.Name.Line
+	case Conditional:
+		if line := ExpressionLine(node.Condition); line > 0 {
+			return line
+		}
+		return node.Question.Line
+	case Call:
+		if line := ExpressionLine(node.Callee); line > 0 {
+			return line
+		}
+		return node.Paren.Line
+	case Get:
+		if line := ExpressionLine(node.Object); line > 0 {
+			return line
+		}
+		return node.Name.Line
+	}
+
+	return 0
+}
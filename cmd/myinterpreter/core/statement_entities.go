@@ -13,7 +13,8 @@ func (s ExpressionStmt) Accept(visitor StatementVisitor) (any, Error) {
 }
 
 type PrintStmt struct {
-	Expr Expression
+	Keyword Token
+	Expr    Expression
 }
 
 func (s PrintStmt) Accept(visitor StatementVisitor) (any, Error) {
@@ -36,3 +37,116 @@ type BlockStmt struct {
 func (s BlockStmt) Accept(visitor StatementVisitor) (any, Error) {
 	return visitor.VisitBlockStmt(s)
 }
+
+type FunctionStmt struct {
+	Name   Token
+	Params []Token
+	Body   []Statement
+}
+
+func (s FunctionStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitFunctionStmt(s)
+}
+
+type ReturnStmt struct {
+	Keyword Token
+	Value   Expression
+}
+
+func (s ReturnStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitReturnStmt(s)
+}
+
+type ThrowStmt struct {
+	Keyword Token
+	Value   Expression
+}
+
+func (s ThrowStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitThrowStmt(s)
+}
+
+// TryStmt is `try { } catch (name) { } finally { }`, where either the catch
+// or the finally clause may be left out. HasCatch tells whether CatchName and
+// CatchBody are set; FinallyBody is nil without a finally clause.
+type TryStmt struct {
+	Keyword     Token
+	Body        []Statement
+	HasCatch    bool
+	CatchName   Token
+	CatchBody   []Statement
+	FinallyBody []Statement
+}
+
+func (s TryStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitTryStmt(s)
+}
+
+type IfStmt struct {
+	Keyword    Token
+	Condition  Expression
+	ThenBranch Statement
+	ElseBranch Statement
+}
+
+func (s IfStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitIfStmt(s)
+}
+
+type WhileStmt struct {
+	Keyword   Token
+	Condition Expression
+	Body      Statement
+}
+
+func (s WhileStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitWhileStmt(s)
+}
+
+// ForStmt is `for (initializer; condition; increment) body`. Any of the three
+// clauses may be nil.
+type ForStmt struct {
+	Keyword     Token
+	Initializer Statement
+	Condition   Expression
+	Increment   Expression
+	Body        Statement
+}
+
+func (s ForStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitForStmt(s)
+}
+
+// ImportStmt is `import "path" as alias;`, binding the whole module to Alias,
+// or `import { name, other as alias } from "path";`, binding the listed
+// names instead. Path is the string token naming the file.
+type ImportStmt struct {
+	Keyword Token
+	Path    Token
+	Alias   Token
+	Names   []ImportName
+}
+
+// ImportName is one name of a selective import. Alias is the name it is
+// bound to, which is Name itself without `as`.
+type ImportName struct {
+	Name  Token
+	Alias Token
+}
+
+func (s ImportStmt) Accept(visitor StatementVisitor) (any, Error) {
+	return visitor.VisitImportStmt(s)
+}
+
+// Bindings returns the names an import declares in the importing scope.
+func (s ImportStmt) Bindings() []Token {
+	if s.Names == nil {
+		return []Token{s.Alias}
+	}
+
+	bindings := []Token{}
+	for _, name := range s.Names {
+		bindings = append(bindings, name.Alias)
+	}
+	return bindings
+}
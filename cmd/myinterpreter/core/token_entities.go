@@ -9,65 +9,94 @@ type tokenType = string
 type keyword = string
 
 const (
-	LEFT_PAREN    tokenType = "LEFT_PAREN"
-	RIGHT_PAREN   tokenType = "RIGHT_PAREN"
-	LEFT_BRACE    tokenType = "LEFT_BRACE"
-	RIGHT_BRACE   tokenType = "RIGHT_BRACE"
-	COMMA         tokenType = "COMMA"
-	DOT           tokenType = "DOT"
-	MINUS         tokenType = "MINUS"
-	PLUS          tokenType = "PLUS"
-	SEMICOLON     tokenType = "SEMICOLON"
-	SLASH         tokenType = "SLASH"
-	STAR          tokenType = "STAR"
-	BANG          tokenType = "BANG"
-	BANG_EQUAL    tokenType = "BANG_EQUAL"
-	EQUAL         tokenType = "EQUAL"
-	EQUAL_EQUAL   tokenType = "EQUAL_EQUAL"
-	GREATER       tokenType = "GREATER"
-	GREATER_EQUAL tokenType = "GREATER_EQUAL"
-	LESS          tokenType = "LESS"
-	LESS_EQUAL    tokenType = "LESS_EQUAL"
-	EOF           tokenType = "EOF"
-	STRING        tokenType = "STRING"
-	NUMBER        tokenType = "NUMBER"
-	IDENTIFIER    tokenType = "IDENTIFIER"
-	AND           keyword   = "AND"
-	CLASS         keyword   = "CLASS"
-	ELSE          keyword   = "ELSE"
-	FALSE         keyword   = "FALSE"
-	FOR           keyword   = "FOR"
-	FUN           keyword   = "FUN"
-	IF            keyword   = "IF"
-	NIL           keyword   = "NIL"
-	OR            keyword   = "OR"
-	PRINT         keyword   = "PRINT"
-	RETURN        keyword   = "RETURN"
-	SUPER         keyword   = "SUPER"
-	THIS          keyword   = "THIS"
-	TRUE          keyword   = "TRUE"
-	VAR           keyword   = "VAR"
-	WHILE         keyword   = "WHILE"
+	LEFT_PAREN        tokenType = "LEFT_PAREN"
+	RIGHT_PAREN       tokenType = "RIGHT_PAREN"
+	LEFT_BRACE        tokenType = "LEFT_BRACE"
+	RIGHT_BRACE       tokenType = "RIGHT_BRACE"
+	COMMA             tokenType = "COMMA"
+	DOT               tokenType = "DOT"
+	MINUS             tokenType = "MINUS"
+	MINUS_MINUS       tokenType = "MINUS_MINUS"
+	MINUS_EQUAL       tokenType = "MINUS_EQUAL"
+	PLUS              tokenType = "PLUS"
+	PLUS_PLUS         tokenType = "PLUS_PLUS"
+	PLUS_EQUAL        tokenType = "PLUS_EQUAL"
+	SEMICOLON         tokenType = "SEMICOLON"
+	QUESTION          tokenType = "QUESTION"
+	QUESTION_QUESTION tokenType = "QUESTION_QUESTION"
+	COLON             tokenType = "COLON"
+	SLASH             tokenType = "SLASH"
+	SLASH_EQUAL       tokenType = "SLASH_EQUAL"
+	STAR              tokenType = "STAR"
+	STAR_EQUAL        tokenType = "STAR_EQUAL"
+	STAR_STAR         tokenType = "STAR_STAR"
+	PERCENT           tokenType = "PERCENT"
+	TILDE             tokenType = "TILDE"
+	TILDE_SLASH       tokenType = "TILDE_SLASH"
+	AMPERSAND         tokenType = "AMPERSAND"
+	PIPE              tokenType = "PIPE"
+	CARET             tokenType = "CARET"
+	BANG              tokenType = "BANG"
+	BANG_EQUAL        tokenType = "BANG_EQUAL"
+	EQUAL             tokenType = "EQUAL"
+	EQUAL_EQUAL       tokenType = "EQUAL_EQUAL"
+	GREATER           tokenType = "GREATER"
+	GREATER_EQUAL     tokenType = "GREATER_EQUAL"
+	LESS              tokenType = "LESS"
+	LESS_EQUAL        tokenType = "LESS_EQUAL"
+	LESS_LESS         tokenType = "LESS_LESS"
+	GREATER_GREATER   tokenType = "GREATER_GREATER"
+	EOF               tokenType = "EOF"
+	STRING            tokenType = "STRING"
+	NUMBER            tokenType = "NUMBER"
+	DECIMAL           tokenType = "DECIMAL"
+	IDENTIFIER        tokenType = "IDENTIFIER"
+	AND               keyword   = "AND"
+	CLASS             keyword   = "CLASS"
+	ELSE              keyword   = "ELSE"
+	FALSE             keyword   = "FALSE"
+	FOR               keyword   = "FOR"
+	FUN               keyword   = "FUN"
+	IF                keyword   = "IF"
+	IMPORT            keyword   = "IMPORT"
+	NIL               keyword   = "NIL"
+	OR                keyword   = "OR"
+	PRINT             keyword   = "PRINT"
+	RETURN            keyword   = "RETURN"
+	SUPER             keyword   = "SUPER"
+	THIS              keyword   = "THIS"
+	THROW             keyword   = "THROW"
+	TRY               keyword   = "TRY"
+	CATCH             keyword   = "CATCH"
+	FINALLY           keyword   = "FINALLY"
+	TRUE              keyword   = "TRUE"
+	VAR               keyword   = "VAR"
+	WHILE             keyword   = "WHILE"
 )
 
 func Keywords() map[string]tokenType {
 	return map[string]tokenType{
-		"and":    AND,
-		"class":  CLASS,
-		"else":   ELSE,
-		"false":  FALSE,
-		"for":    FOR,
-		"fun":    FUN,
-		"if":     IF,
-		"nil":    NIL,
-		"or":     OR,
-		"print":  PRINT,
-		"return": RETURN,
-		"super":  SUPER,
-		"this":   THIS,
-		"true":   TRUE,
-		"var":    VAR,
-		"while":  WHILE,
+		"and":     AND,
+		"class":   CLASS,
+		"else":    ELSE,
+		"false":   FALSE,
+		"for":     FOR,
+		"fun":     FUN,
+		"if":      IF,
+		"import":  IMPORT,
+		"nil":     NIL,
+		"or":      OR,
+		"print":   PRINT,
+		"return":  RETURN,
+		"super":   SUPER,
+		"this":    THIS,
+		"throw":   THROW,
+		"try":     TRY,
+		"catch":   CATCH,
+		"finally": FINALLY,
+		"true":    TRUE,
+		"var":     VAR,
+		"while":   WHILE,
 	}
 }
 
@@ -76,6 +105,17 @@ type Token struct {
 	Lexeme  string
 	Literal any
 	Line    int
+	// Column is the byte offset of the token from the start of its line.
+	Column int
+}
+
+// Comment is a `//` comment kept by the scanner as trivia for tools that
+// reprint source, like the formatter. Trailing comments follow code on the
+// same line.
+type Comment struct {
+	Text     string
+	Line     int
+	Trailing bool
 }
 
 func (t Token) String() string {
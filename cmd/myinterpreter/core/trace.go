@@ -0,0 +1,48 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TraceFrame is one entry of a runtime stack trace: the function that was
+// running and the line it had reached in Script.
+type TraceFrame struct {
+	Function string
+	Script   string
+	Line     int
+}
+
+func (f TraceFrame) String() string {
+	return fmt.Sprintf("at %s (%s:%d)", f.Function, f.Script, f.Line)
+}
+
+// Error lets embedders treat a core.Error as a regular Go error.
+func (e Error) Error() string {
+	return fmt.Sprintf("[line %d] Error: %v", e.Line, e.Err)
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// StackTrace formats Trace one frame per line, innermost first. Runs of
+// identical frames, as left by deep recursion, are folded into one line.
+func (e Error) StackTrace() string {
+	lines := []string{}
+	for index := 0; index < len(e.Trace); {
+		frame := e.Trace[index]
+		repeats := 0
+		for index+repeats+1 < len(e.Trace) && e.Trace[index+repeats+1] == frame {
+			repeats++
+		}
+
+		lines = append(lines, "    "+frame.String())
+		if repeats > 0 {
+			lines = append(lines, fmt.Sprintf("    ... repeated %d more times", repeats))
+		}
+		index += repeats + 1
+	}
+
+	return strings.Join(lines, "\n")
+}
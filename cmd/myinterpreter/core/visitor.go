@@ -7,6 +7,12 @@ type ExpressionVisitor interface {
 	VisitUnaryExpr(expr Unary) (any, Error)
 	VisitVariableExpr(expr Variable) (any, Error)
 	VisitAssignExpr(expr Assign) (any, Error)
+	VisitCompoundAssignExpr(expr CompoundAssign) (any, Error)
+	VisitIncrementExpr(expr Increment) (any, Error)
+	VisitConditionalExpr(expr Conditional) (any, Error)
+	VisitLogicalExpr(expr Logical) (any, Error)
+	VisitCallExpr(expr Call) (any, Error)
+	VisitGetExpr(expr Get) (any, Error)
 }
 
 type StatementVisitor interface {
@@ -14,4 +20,12 @@ type StatementVisitor interface {
 	VisitPrintStmt(stmt PrintStmt) (any, Error)
 	VisitVarStmt(stmt VarStmt) (any, Error)
 	VisitBlockStmt(stmt BlockStmt) (any, Error)
+	VisitFunctionStmt(stmt FunctionStmt) (any, Error)
+	VisitReturnStmt(stmt ReturnStmt) (any, Error)
+	VisitThrowStmt(stmt ThrowStmt) (any, Error)
+	VisitTryStmt(stmt TryStmt) (any, Error)
+	VisitIfStmt(stmt IfStmt) (any, Error)
+	VisitWhileStmt(stmt WhileStmt) (any, Error)
+	VisitForStmt(stmt ForStmt) (any, Error)
+	VisitImportStmt(stmt ImportStmt) (any, Error)
 }
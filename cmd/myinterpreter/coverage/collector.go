@@ -0,0 +1,150 @@
+package coverage
+
+import (
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// Collector is an interpreter hook counting the statements and branches run
+// from the script at path, leaving out the other files of the program. It
+// knows every statement and decision of the script beforehand, so the ones
+// never reached show up as uncovered.
+type Collector struct {
+	path     string
+	source   string
+	lines    map[int]int
+	branches map[[2]int]*Branch
+}
+
+func CreateCollector(path string, statements []core.Statement) *Collector {
+	c := &Collector{path: path, lines: map[int]int{}, branches: map[[2]int]*Branch{}}
+	c.statements(statements)
+	return c
+}
+
+func (c *Collector) BeforeStatement(interpreter *visitor.Interpreter, stmt core.Statement, line int) error {
+	if line > 0 && interpreter.CurrentScript() == c.path {
+		c.lines[line]++
+	}
+	return nil
+}
+
+func (c *Collector) Branch(interpreter *visitor.Interpreter, token core.Token, taken bool) {
+	if interpreter.CurrentScript() != c.path {
+		return
+	}
+	branch := c.decision(token)
+	if taken {
+		branch.Taken++
+	} else {
+		branch.NotTaken++
+	}
+}
+
+// KeepSource stores the text of the script in its profile, for scripts the
+// report can't read back from path.
+func (c *Collector) KeepSource(source []byte) {
+	c.source = string(source)
+}
+
+// Profile returns the coverage collected so far.
+func (c *Collector) Profile() Profile {
+	lines := map[int]int{}
+	for line, count := range c.lines {
+		lines[line] = count
+	}
+
+	return Profile{Files: []File{{Path: c.path, Source: c.source, Lines: lines, Branches: sortedBranches(c.branches)}}}
+}
+
+func (c *Collector) decision(token core.Token) *Branch {
+	key := [2]int{token.Line, token.Column}
+	if c.branches[key] == nil {
+		c.branches[key] = &Branch{Line: token.Line, Column: token.Column}
+	}
+	return c.branches[key]
+}
+
+// statements registers the statement lines and decisions of a syntax tree.
+func (c *Collector) statements(statements []core.Statement) {
+	for _, statement := range statements {
+		line := core.StatementLine(statement)
+		if _, seen := c.lines[line]; line > 0 && !seen {
+			c.lines[line] = 0
+		}
+
+		switch node := statement.(type) {
+		case core.ExpressionStmt:
+			c.expression(node.Expr)
+		case core.PrintStmt:
+			c.expression(node.Expr)
+		case core.VarStmt:
+			c.expression(node.Initializer)
+		case core.BlockStmt:
+			c.statements(node.Statements)
+		case core.FunctionStmt:
+			c.statements(node.Body)
+		case core.ReturnStmt:
+			c.expression(node.Value)
+		case core.ThrowStmt:
+			c.expression(node.Value)
+		case core.TryStmt:
+			c.statements(node.Body)
+			c.statements(node.CatchBody)
+			c.statements(node.FinallyBody)
+		case core.IfStmt:
+			c.decision(node.Keyword)
+			c.expression(node.Condition)
+			c.statements([]core.Statement{node.ThenBranch})
+			if node.ElseBranch != nil {
+				c.statements([]core.Statement{node.ElseBranch})
+			}
+		case core.WhileStmt:
+			c.decision(node.Keyword)
+			c.expression(node.Condition)
+			c.statements([]core.Statement{node.Body})
+		case core.ForStmt:
+			if node.Initializer != nil {
+				c.statements([]core.Statement{node.Initializer})
+			}
+			if node.Condition != nil {
+				c.decision(node.Keyword)
+				c.expression(node.Condition)
+			}
+			c.expression(node.Increment)
+			c.statements([]core.Statement{node.Body})
+		}
+	}
+}
+
+func (c *Collector) expression(expr core.Expression) {
+	switch node := expr.(type) {
+	case core.Binary:
+		c.expression(node.Left)
+		c.expression(node.Right)
+	case core.Logical:
+		c.decision(node.Operator)
+		c.expression(node.Left)
+		c.expression(node.Right)
+	case core.Conditional:
+		c.decision(node.Question)
+		c.expression(node.Condition)
+		c.expression(node.Then)
+		c.expression(node.Else)
+	case core.Grouping:
+		c.expression(node.Expr)
+	case core.Unary:
+		c.expression(node.Right)
+	case core.Assign:
+		c.expression(node.Value)
+	case core.CompoundAssign:
+		c.expression(node.Value)
+	case core.Call:
+		c.expression(node.Callee)
+		for _, argument := range node.Arguments {
+			c.expression(argument)
+		}
+	case core.Get:
+		c.expression(node.Object)
+	}
+}
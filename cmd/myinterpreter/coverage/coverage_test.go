@@ -0,0 +1,166 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+const program = `fun sign(x) {
+  if (x < 0) {
+    return -1;
+  }
+  return x > 0 ? 1 : 0;
+}
+print sign(2);
+`
+
+// collect runs source and returns its coverage.
+func collect(t *testing.T, source string) Profile {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(source))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", parseErr.Err)
+	}
+
+	collector := CreateCollector("script.lox", statements)
+	interpreter := visitor.CreateInterpreter()
+	interpreter.SetScriptName("script.lox")
+	interpreter.SetOutput(&bytes.Buffer{})
+	interpreter.SetHook(collector)
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+		}
+	}
+	return collector.Profile()
+}
+
+func TestCollectorCountsStatementsAndBranches(t *testing.T) {
+	file := collect(t, program).Files[0]
+
+	if expected := map[int]int{1: 1, 2: 1, 3: 0, 5: 1, 7: 1}; !reflect.DeepEqual(file.Lines, expected) {
+		t.Fatalf("expecting lines %v, but got: %v", expected, file.Lines)
+	}
+
+	expected := []Branch{{Line: 2, Column: 2, Taken: 0, NotTaken: 1}, {Line: 5, Column: 15, Taken: 1, NotTaken: 0}}
+	if !reflect.DeepEqual(file.Branches, expected) {
+		t.Fatalf("expecting branches %v, but got: %v", expected, file.Branches)
+	}
+
+	lines, coveredLines, branches, coveredBranches := file.Summary()
+	if lines != 5 || coveredLines != 4 || branches != 4 || coveredBranches != 2 {
+		t.Fatalf("expecting 4/5 lines and 2/4 branches, but got: %d/%d and %d/%d", coveredLines, lines, coveredBranches, branches)
+	}
+}
+
+func TestMergeAddsUpRuns(t *testing.T) {
+	merged := Merge(collect(t, program), collect(t, strings.Replace(program, "sign(2)", "sign(-2)", 1)))
+	file := merged.Files[0]
+
+	if len(merged.Files) != 1 || file.Lines[3] != 1 || file.Lines[1] != 2 {
+		t.Fatalf("expecting the runs to be added up, but got: %v", merged)
+	}
+	if file.Branches[0].Taken != 1 || file.Branches[0].NotTaken != 1 {
+		t.Fatalf("expecting both ways of the if to be covered, but got: %v", file.Branches)
+	}
+
+	var out bytes.Buffer
+	if err := merged.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadProfile(&out)
+	if err != nil || !reflect.DeepEqual(read, merged) {
+		t.Fatalf("expecting the profile to read back, but got: %v (%v)", read, err)
+	}
+}
+
+func TestLCOV(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteLCOV(&out, collect(t, program)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `TN:
+SF:script.lox
+BRDA:2,0,0,0
+BRDA:2,0,1,1
+BRDA:5,0,0,1
+BRDA:5,0,1,0
+BRF:4
+BRH:2
+DA:1,1
+DA:2,1
+DA:3,0
+DA:5,1
+DA:7,1
+LF:5
+LH:4
+end_of_record
+`
+	if out.String() != expected {
+		t.Fatalf("expecting:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestHTMLMarksLines(t *testing.T) {
+	var out bytes.Buffer
+	source := func(path string) ([]byte, error) { return []byte(program), nil }
+	if err := WriteHTML(&out, collect(t, program), source); err != nil {
+		t.Fatal(err)
+	}
+
+	page := out.String()
+	for _, expected := range []string{
+		`<h2>script.lox (80.0%)</h2>`,
+		`<tr class="partial"><td class="number">2</td><td class="count">1</td><td class="text">  if (x &lt; 0) {</td></tr>`,
+		`<tr class="uncovered"><td class="number">3</td><td class="count">0</td>`,
+		`<tr class=""><td class="number">4</td><td class="count"></td>`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("expecting the page to contain %q, but got:\n%s", expected, page)
+		}
+	}
+}
+
+func TestHTMLShowsInlineScripts(t *testing.T) {
+	profile := collect(t, program)
+	profile.Files[0].Path = "<inline>"
+	profile.Files[0].Source = program
+
+	var stored bytes.Buffer
+	if err := Merge(profile).Write(&stored); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadProfile(&stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	noFile := func(path string) ([]byte, error) { return nil, fmt.Errorf("no file %s", path) }
+	if err := WriteHTML(&out, read, noFile); err != nil {
+		t.Fatalf("expecting the source kept in the profile to be used, but got: %v", err)
+	}
+
+	page := out.String()
+	for _, expected := range []string{
+		`<h2>&lt;inline&gt; (80.0%)</h2>`,
+		`<tr class="covered"><td class="number">7</td><td class="count">1</td><td class="text">print sign(2);</td></tr>`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("expecting the page to contain %q, but got:\n%s", expected, page)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package coverage records which statements and branches of Lox scripts run
+// and reports it per line, as a summary, LCOV or HTML.
+package coverage
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Profile is the coverage of one or more runs, as stored in a coverage file.
+type Profile struct {
+	Files []File `json:"files"`
+}
+
+// File is the coverage of one script. Lines maps every line a statement
+// starts on to the number of statements run there. Source is the text of a
+// script not read from a file, such as `run -e` code, which the HTML report
+// has no other way to show.
+type File struct {
+	Path     string      `json:"path"`
+	Source   string      `json:"source,omitempty"`
+	Lines    map[int]int `json:"lines"`
+	Branches []Branch    `json:"branches"`
+}
+
+// Branch counts which way a decision went, the decision being identified by
+// the position of its token.
+type Branch struct {
+	Line     int `json:"line"`
+	Column   int `json:"column"`
+	Taken    int `json:"taken"`
+	NotTaken int `json:"notTaken"`
+}
+
+func ReadProfile(r io.Reader) (Profile, error) {
+	var profile Profile
+	err := json.NewDecoder(r).Decode(&profile)
+	return profile, err
+}
+
+func (p Profile) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// Merge adds up profiles, for instance those of every script of a test
+// suite, sorting files by path.
+func Merge(profiles ...Profile) Profile {
+	files := map[string]*File{}
+	branches := map[string]map[[2]int]*Branch{}
+
+	for _, profile := range profiles {
+		for _, file := range profile.Files {
+			merged := files[file.Path]
+			if merged == nil {
+				merged = &File{Path: file.Path, Lines: map[int]int{}}
+				files[file.Path] = merged
+				branches[file.Path] = map[[2]int]*Branch{}
+			}
+			if merged.Source == "" {
+				merged.Source = file.Source
+			}
+
+			for line, count := range file.Lines {
+				merged.Lines[line] += count
+			}
+			for _, branch := range file.Branches {
+				key := [2]int{branch.Line, branch.Column}
+				if branches[file.Path][key] == nil {
+					branches[file.Path][key] = &Branch{Line: branch.Line, Column: branch.Column}
+				}
+				branches[file.Path][key].Taken += branch.Taken
+				branches[file.Path][key].NotTaken += branch.NotTaken
+			}
+		}
+	}
+
+	merged := Profile{Files: []File{}}
+	for path, file := range files {
+		file.Branches = sortedBranches(branches[path])
+		merged.Files = append(merged.Files, *file)
+	}
+	sort.Slice(merged.Files, func(a, b int) bool { return merged.Files[a].Path < merged.Files[b].Path })
+	return merged
+}
+
+func sortedBranches(branches map[[2]int]*Branch) []Branch {
+	sorted := []Branch{}
+	for _, branch := range branches {
+		sorted = append(sorted, *branch)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].Line != sorted[b].Line {
+			return sorted[a].Line < sorted[b].Line
+		}
+		return sorted[a].Column < sorted[b].Column
+	})
+	return sorted
+}
+
+// Summary counts the statement lines and branch directions of a file, and
+// how many of them were covered. Every decision has two directions.
+func (f File) Summary() (lines int, coveredLines int, branches int, coveredBranches int) {
+	for _, count := range f.Lines {
+		lines++
+		if count > 0 {
+			coveredLines++
+		}
+	}
+
+	for _, branch := range f.Branches {
+		branches += 2
+		if branch.Taken > 0 {
+			coveredBranches++
+		}
+		if branch.NotTaken > 0 {
+			coveredBranches++
+		}
+	}
+	return lines, coveredLines, branches, coveredBranches
+}
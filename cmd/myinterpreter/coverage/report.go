@@ -0,0 +1,144 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteLCOV writes a profile in the LCOV tracefile format. Each decision is
+// a block of two branches: the way taken first, then the other one.
+func WriteLCOV(w io.Writer, profile Profile) error {
+	var b strings.Builder
+	for _, file := range profile.Files {
+		fmt.Fprintf(&b, "TN:\nSF:%s\n", file.Path)
+
+		blocks := map[int]int{}
+		for _, branch := range file.Branches {
+			block := blocks[branch.Line]
+			blocks[branch.Line]++
+			fmt.Fprintf(&b, "BRDA:%d,%d,0,%s\n", branch.Line, block, branchCount(branch, branch.Taken))
+			fmt.Fprintf(&b, "BRDA:%d,%d,1,%s\n", branch.Line, block, branchCount(branch, branch.NotTaken))
+		}
+
+		lines, coveredLines, branches, coveredBranches := file.Summary()
+		fmt.Fprintf(&b, "BRF:%d\nBRH:%d\n", branches, coveredBranches)
+		for _, line := range sortedLines(file) {
+			fmt.Fprintf(&b, "DA:%d,%d\n", line, file.Lines[line])
+		}
+		fmt.Fprintf(&b, "LF:%d\nLH:%d\nend_of_record\n", lines, coveredLines)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// branchCount is `-` for a decision that was never reached, as LCOV wants.
+func branchCount(branch Branch, count int) string {
+	if branch.Taken+branch.NotTaken == 0 {
+		return "-"
+	}
+	return fmt.Sprint(count)
+}
+
+func sortedLines(file File) []int {
+	lines := make([]int, 0, len(file.Lines))
+	for line := range file.Lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+type htmlFile struct {
+	Path    string
+	Percent string
+	Lines   []htmlLine
+}
+
+type htmlLine struct {
+	Number int
+	Count  string
+	Class  string
+	Text   string
+}
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Lox coverage</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; font-family: monospace; }
+td { padding: 0 0.5em; white-space: pre; }
+td.number, td.count { color: #888; text-align: right; }
+tr.covered td.text { background: #dfd; }
+tr.uncovered td.text { background: #fdd; }
+tr.partial td.text { background: #ffd; }
+</style>
+</head>
+<body>
+{{range .}}<h2>{{.Path}} ({{.Percent}})</h2>
+<table>
+{{range .Lines}}<tr class="{{.Class}}"><td class="number">{{.Number}}</td><td class="count">{{.Count}}</td><td class="text">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes a page showing the source of every file of a profile,
+// with covered lines in green, lines with a branch never taken in yellow
+// and lines never run in red. source reads the text of a file whose profile
+// doesn't carry it.
+func WriteHTML(w io.Writer, profile Profile, source func(path string) ([]byte, error)) error {
+	files := []htmlFile{}
+	for _, file := range profile.Files {
+		text := []byte(file.Source)
+		if file.Source == "" {
+			var err error
+			if text, err = source(file.Path); err != nil {
+				return err
+			}
+		}
+
+		partial := map[int]bool{}
+		for _, branch := range file.Branches {
+			if branch.Taken == 0 || branch.NotTaken == 0 {
+				partial[branch.Line] = true
+			}
+		}
+
+		lines, coveredLines, _, _ := file.Summary()
+		page := htmlFile{Path: file.Path, Percent: Percent(coveredLines, lines)}
+		for index, line := range strings.Split(strings.TrimSuffix(string(text), "\n"), "\n") {
+			number := index + 1
+			entry := htmlLine{Number: number, Text: line}
+			if count, ok := file.Lines[number]; ok {
+				entry.Count = fmt.Sprint(count)
+				switch {
+				case count == 0:
+					entry.Class = "uncovered"
+				case partial[number]:
+					entry.Class = "partial"
+				default:
+					entry.Class = "covered"
+				}
+			}
+			page.Lines = append(page.Lines, entry)
+		}
+		files = append(files, page)
+	}
+
+	return htmlTemplate.Execute(w, files)
+}
+
+// Percent formats a ratio, counting nothing to cover as fully covered.
+func Percent(covered int, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(covered)/float64(total))
+}
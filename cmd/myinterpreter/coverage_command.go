@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/coverage"
+)
+
+// coverageCommand implements `coverage [--html=file] [--lcov=file] [--min=percent]
+// [--min-branches=percent] <profile>...`. It merges the profiles written by
+// `run --coverage`, prints the coverage of every file and exits with 1 when
+// the total is below a threshold.
+func coverageCommand(args []string) {
+	flags := flag.NewFlagSet("coverage", flag.ExitOnError)
+	htmlOutput := flags.String("html", "", "file to write an HTML report to")
+	lcovOutput := flags.String("lcov", "", "file to write an LCOV tracefile to")
+	minLines := flags.Float64("min", 0, "minimum percentage of statement lines covered")
+	minBranches := flags.Float64("min-branches", 0, "minimum percentage of branches covered")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh coverage [--html=file] [--lcov=file] [--min=percent] <profile>...")
+		os.Exit(1)
+	}
+
+	profiles := []coverage.Profile{}
+	for _, filename := range flags.Args() {
+		file, err := os.Open(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+		profile, err := coverage.ReadProfile(file)
+		file.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid coverage profile %s: %v\n", filename, err)
+			os.Exit(1)
+		}
+		profiles = append(profiles, profile)
+	}
+	profile := coverage.Merge(profiles...)
+
+	var lines, coveredLines, branches, coveredBranches int
+	for _, file := range profile.Files {
+		fileLines, fileCoveredLines, fileBranches, fileCoveredBranches := file.Summary()
+		fmt.Printf("%s: %s of statements, %s of branches\n", file.Path,
+			coverage.Percent(fileCoveredLines, fileLines), coverage.Percent(fileCoveredBranches, fileBranches))
+
+		lines += fileLines
+		coveredLines += fileCoveredLines
+		branches += fileBranches
+		coveredBranches += fileCoveredBranches
+	}
+	fmt.Printf("total: %s of statements, %s of branches\n",
+		coverage.Percent(coveredLines, lines), coverage.Percent(coveredBranches, branches))
+
+	if *lcovOutput != "" {
+		writeReport(*lcovOutput, func(file *os.File) error { return coverage.WriteLCOV(file, profile) })
+	}
+	if *htmlOutput != "" {
+		writeReport(*htmlOutput, func(file *os.File) error { return coverage.WriteHTML(file, profile, os.ReadFile) })
+	}
+
+	if below(coveredLines, lines, *minLines) || below(coveredBranches, branches, *minBranches) {
+		fmt.Fprintln(os.Stderr, "Coverage is below the minimum.")
+		os.Exit(1)
+	}
+}
+
+func writeReport(filename string, write func(file *os.File) error) {
+	file, err := os.Create(filename)
+	if err == nil {
+		err = write(file)
+		file.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func below(covered int, total int, minimum float64) bool {
+	return total > 0 && 100*float64(covered)/float64(total) < minimum
+}
@@ -0,0 +1,353 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/framing"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// Adapter is a debug adapter for Lox. It runs the launched script on its own
+// goroutine while it keeps answering the client, and the script blocks in
+// the interpreter's statement hook whenever it is stopped.
+type Adapter struct {
+	writer  io.Writer
+	writeMu sync.Mutex
+	seq     int
+
+	program    string
+	statements []core.Statement
+	lines      map[int]bool
+	launched   bool
+	configured bool
+	done       chan struct{}
+
+	// mu guards the execution state shared with the script's goroutine.
+	mu          sync.Mutex
+	resumed     *sync.Cond
+	breakpoints map[int]bool
+	execution   execution
+}
+
+func CreateAdapter() *Adapter {
+	adapter := &Adapter{breakpoints: map[int]bool{}}
+	adapter.resumed = sync.NewCond(&adapter.mu)
+	return adapter
+}
+
+// Serve handles requests from in until the client disconnects or closes the
+// stream, writing responses and events to out. A script still running by
+// then is terminated.
+func (a *Adapter) Serve(in io.Reader, out io.Writer) error {
+	a.writer = out
+	reader := bufio.NewReader(in)
+	defer a.terminate()
+
+	for {
+		body, err := framing.Read(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return fmt.Errorf("Invalid debug adapter message: %v.", err)
+		}
+
+		result, handleErr := a.handle(req)
+		reply := response{Type: "response", RequestSeq: req.Seq, Success: handleErr == nil, Command: req.Command, Body: result}
+		if handleErr != nil {
+			reply.Message = handleErr.Error()
+		}
+		if err := a.send(&reply.Seq, &reply); err != nil {
+			return err
+		}
+
+		switch req.Command {
+		case "initialize":
+			err = a.event("initialized", nil)
+		case "launch", "configurationDone":
+			err = a.start()
+		case "disconnect", "terminate":
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// send numbers and writes a message. Events come from both goroutines, so
+// writes are serialized.
+func (a *Adapter) send(seq *int, msg any) error {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+
+	a.seq++
+	*seq = a.seq
+	return framing.Write(a.writer, msg)
+}
+
+func (a *Adapter) event(name string, body any) error {
+	msg := event{Type: "event", Event: name, Body: body}
+	return a.send(&msg.Seq, &msg)
+}
+
+// handle runs a request, returning the body of its response or the error
+// that makes it fail.
+func (a *Adapter) handle(req request) (any, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsTerminateRequest":         true,
+		}, nil
+
+	case "launch":
+		var args launchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return nil, a.launch(args)
+
+	case "configurationDone":
+		a.configured = true
+		return nil, nil
+
+	case "setBreakpoints":
+		var args setBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return map[string]any{"breakpoints": a.setBreakpoints(args.Breakpoints)}, nil
+
+	case "threads":
+		return map[string]any{"threads": []Thread{{ID: threadID, Name: "main"}}}, nil
+
+	case "stackTrace":
+		frames, err := a.stackTrace()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+
+	case "scopes":
+		var args scopesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		scopes, err := a.scopes(args.FrameID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"scopes": scopes}, nil
+
+	case "variables":
+		var args variablesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		variables, err := a.variables(args.VariablesReference)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"variables": variables}, nil
+
+	case "continue":
+		return map[string]any{"allThreadsContinued": true}, a.resume(running)
+
+	case "next":
+		return nil, a.resume(steppingOver)
+
+	case "stepIn":
+		return nil, a.resume(steppingIn)
+
+	case "stepOut":
+		return nil, a.resume(steppingOut)
+
+	case "pause":
+		a.pause()
+		return nil, nil
+
+	case "disconnect", "terminate":
+		a.terminate()
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported request: %s.", req.Command)
+}
+
+// launch loads the program. It only starts running once the client is done
+// setting breakpoints.
+func (a *Adapter) launch(args launchArguments) error {
+	if a.launched {
+		return fmt.Errorf("A program is already launched.")
+	}
+
+	source, err := os.ReadFile(args.Program)
+	if err != nil {
+		return fmt.Errorf("Error reading file: %v.", err)
+	}
+
+	tokens, errs := scanner.ScanFile(source)
+	if len(errs) != 0 {
+		return fmt.Errorf("[line %d] Error: %v", errs[0].Line, errs[0].Err)
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		return fmt.Errorf("[line %d] Error: %v", parseErr.Line, parseErr.Err)
+	}
+
+	a.program = args.Program
+	a.statements = statements
+	a.lines = map[int]bool{}
+	statementLines(statements, a.lines)
+	a.launched = true
+
+	a.mu.Lock()
+	if args.StopOnEntry {
+		a.execution.mode = enteringScript
+	}
+	a.mu.Unlock()
+	return nil
+}
+
+// setBreakpoints replaces the breakpoints. Only lines a statement starts on
+// can be stopped at, so the others are reported as unverified.
+func (a *Adapter) setBreakpoints(requested []SourceBreakpoint) []Breakpoint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.breakpoints = map[int]bool{}
+	breakpoints := []Breakpoint{}
+	for _, requested := range requested {
+		breakpoint := Breakpoint{Line: requested.Line, Verified: a.lines[requested.Line]}
+		if breakpoint.Verified {
+			a.breakpoints[requested.Line] = true
+		} else {
+			breakpoint.Message = "No statement starts on this line."
+		}
+		breakpoints = append(breakpoints, breakpoint)
+	}
+	return breakpoints
+}
+
+func source(path string) Source {
+	return Source{Name: filepath.Base(path), Path: path}
+}
+
+func (a *Adapter) stackTrace() ([]StackFrame, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.execution.stopped {
+		return nil, errNotStopped
+	}
+
+	frames := []StackFrame{}
+	for id, frame := range a.execution.interpreter.Frames(a.execution.line) {
+		frames = append(frames, StackFrame{ID: id, Name: frame.Function, Source: source(frame.Script), Line: frame.Line, Column: 1})
+	}
+	return frames, nil
+}
+
+// scopes offers the environments visible from a frame as its locals, every
+// environment nested inside the globals, and the globals.
+func (a *Adapter) scopes(frameID int) ([]Scope, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.execution.stopped {
+		return nil, errNotStopped
+	}
+	frames := a.execution.interpreter.Frames(a.execution.line)
+	if frameID < 0 || frameID >= len(frames) {
+		return nil, fmt.Errorf("Unknown frame: %d.", frameID)
+	}
+
+	locals := []*environment.Environment{}
+	env := frames[frameID].Environment
+	for ; env.Enclosing() != nil; env = env.Enclosing() {
+		locals = append(locals, env)
+	}
+
+	scopes := []Scope{}
+	if len(locals) > 0 {
+		scopes = append(scopes, Scope{Name: "Locals", VariablesReference: a.execution.reference(locals)})
+	}
+	return append(scopes, Scope{Name: "Globals", VariablesReference: a.execution.reference([]*environment.Environment{env})}), nil
+}
+
+// variables lists the variables of a scope. Inner environments come first,
+// so a shadowed variable shows the value the script would read.
+func (a *Adapter) variables(reference int) ([]Variable, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.execution.stopped {
+		return nil, errNotStopped
+	}
+	if reference < 1 || reference > len(a.execution.references) {
+		return nil, fmt.Errorf("Unknown variables reference: %d.", reference)
+	}
+
+	seen := map[string]bool{}
+	variables := []Variable{}
+	for _, env := range a.execution.references[reference-1] {
+		for _, name := range env.Names() {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			value, _ := env.Lookup(name)
+			variables = append(variables, Variable{Name: name, Value: visitor.Stringify(value)})
+		}
+	}
+	return variables, nil
+}
+
+// statementLines records the line every statement starts on, nested ones
+// included.
+func statementLines(statements []core.Statement, lines map[int]bool) {
+	for _, statement := range statements {
+		if line := core.StatementLine(statement); line > 0 {
+			lines[line] = true
+		}
+
+		switch node := statement.(type) {
+		case core.BlockStmt:
+			statementLines(node.Statements, lines)
+		case core.FunctionStmt:
+			statementLines(node.Body, lines)
+		case core.IfStmt:
+			statementLines([]core.Statement{node.ThenBranch}, lines)
+			if node.ElseBranch != nil {
+				statementLines([]core.Statement{node.ElseBranch}, lines)
+			}
+		case core.WhileStmt:
+			statementLines([]core.Statement{node.Body}, lines)
+		case core.ForStmt:
+			if node.Initializer != nil {
+				statementLines([]core.Statement{node.Initializer}, lines)
+			}
+			statementLines([]core.Statement{node.Body}, lines)
+		case core.TryStmt:
+			statementLines(node.Body, lines)
+			statementLines(node.CatchBody, lines)
+			statementLines(node.FinallyBody, lines)
+		}
+	}
+}
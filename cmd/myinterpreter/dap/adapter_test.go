@@ -0,0 +1,240 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/framing"
+)
+
+const program = `var total = 0;
+fun add(amount) {
+  var doubled = amount * 2;
+  total = total + doubled;
+}
+add(1);
+add(2);
+print total;
+`
+
+// client drives an adapter the way an editor would, one message at a time,
+// since the script runs concurrently with the conversation.
+type client struct {
+	t      *testing.T
+	writer io.Writer
+	reader *bufio.Reader
+	seq    int
+	output string
+	served chan error
+}
+
+func startSession(t *testing.T, source string) (*client, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "script.lox")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	c := &client{t: t, writer: inWriter, reader: bufio.NewReader(outReader), served: make(chan error, 1)}
+
+	adapter := CreateAdapter()
+	go func() {
+		c.served <- adapter.Serve(inReader, outWriter)
+		outWriter.Close()
+	}()
+
+	c.request("initialize", map[string]any{"adapterID": "lox"})
+	c.event("initialized")
+	return c, path
+}
+
+// request sends a request and returns the body of its successful response.
+func (c *client) request(command string, arguments any) map[string]any {
+	c.t.Helper()
+
+	c.seq++
+	framing.Write(c.writer, map[string]any{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+
+	msg := c.next(func(msg map[string]any) bool {
+		return msg["type"] == "response" && msg["request_seq"] == float64(c.seq)
+	})
+	if msg["success"] != true {
+		c.t.Fatalf("expecting %s to succeed, but got: %v", command, msg)
+	}
+	body, _ := msg["body"].(map[string]any)
+	return body
+}
+
+// event waits for an event, collecting the output the script prints meanwhile.
+func (c *client) event(name string) map[string]any {
+	c.t.Helper()
+
+	msg := c.next(func(msg map[string]any) bool { return msg["type"] == "event" && msg["event"] == name })
+	body, _ := msg["body"].(map[string]any)
+	return body
+}
+
+func (c *client) next(match func(map[string]any) bool) map[string]any {
+	c.t.Helper()
+
+	for {
+		body, err := framing.Read(c.reader)
+		if err != nil {
+			c.t.Fatalf("was not expecting any errors reading messages, but got: %v", err)
+		}
+
+		var msg map[string]any
+		json.Unmarshal(body, &msg)
+		if msg["event"] == "output" {
+			c.output += msg["body"].(map[string]any)["output"].(string)
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+// stoppedAt waits for the script to stop and returns the function and line
+// of every frame.
+func (c *client) stoppedAt(reason string) [][2]any {
+	c.t.Helper()
+
+	if body := c.event("stopped"); body["reason"] != reason {
+		c.t.Fatalf("expecting to stop for %s, but got: %v", reason, body)
+	}
+
+	frames := [][2]any{}
+	for _, frame := range c.request("stackTrace", map[string]any{"threadId": threadID})["stackFrames"].([]any) {
+		frame := frame.(map[string]any)
+		frames = append(frames, [2]any{frame["name"], frame["line"]})
+	}
+	return frames
+}
+
+func (c *client) disconnect() {
+	c.t.Helper()
+
+	c.request("disconnect", map[string]any{})
+	if err := <-c.served; err != nil {
+		c.t.Fatalf("was not expecting any errors, but got: %v", err)
+	}
+}
+
+func TestBreakpointsStopWithVariables(t *testing.T) {
+	c, path := startSession(t, program)
+	c.request("launch", map[string]any{"program": path})
+
+	breakpoints := c.request("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": path},
+		"breakpoints": []any{map[string]any{"line": 4}, map[string]any{"line": 5}},
+	})["breakpoints"].([]any)
+	if breakpoints[0].(map[string]any)["verified"] != true || breakpoints[1].(map[string]any)["verified"] != false {
+		t.Fatalf("expecting only the statement line to be verified, but got: %v", breakpoints)
+	}
+	c.request("configurationDone", nil)
+
+	frames := c.stoppedAt("breakpoint")
+	if expected := [][2]any{{"add", 4.0}, {"<script>", 6.0}}; !reflect.DeepEqual(frames, expected) {
+		t.Fatalf("expecting frames %v, but got: %v", expected, frames)
+	}
+
+	scopes := c.request("scopes", map[string]any{"frameId": 0})["scopes"].([]any)
+	if len(scopes) != 2 || scopes[0].(map[string]any)["name"] != "Locals" || scopes[1].(map[string]any)["name"] != "Globals" {
+		t.Fatalf("expecting locals and globals, but got: %v", scopes)
+	}
+
+	found := map[string]any{}
+	for _, scope := range scopes {
+		reference := scope.(map[string]any)["variablesReference"]
+		for _, variable := range c.request("variables", map[string]any{"variablesReference": reference})["variables"].([]any) {
+			variable := variable.(map[string]any)
+			found[variable["name"].(string)] = variable["value"]
+		}
+	}
+	for name, value := range map[string]string{"amount": "1", "doubled": "2", "total": "0"} {
+		if found[name] != value {
+			t.Fatalf("expecting %s to be %s, but got: %v", name, value, found)
+		}
+	}
+
+	c.request("continue", map[string]any{"threadId": threadID})
+	if frames := c.stoppedAt("breakpoint"); frames[1][1] != 7.0 {
+		t.Fatalf("expecting to stop in the second call, but got: %v", frames)
+	}
+
+	c.request("continue", map[string]any{"threadId": threadID})
+	if body := c.event("exited"); body["exitCode"] != 0.0 {
+		t.Fatalf("expecting the script to exit cleanly, but got: %v", body)
+	}
+	if c.output != "6\n" {
+		t.Fatalf("expecting the printed total, but got: %q", c.output)
+	}
+	c.disconnect()
+}
+
+func TestSteppingInOverAndOut(t *testing.T) {
+	c, path := startSession(t, program)
+	c.request("launch", map[string]any{"program": path, "stopOnEntry": true})
+	c.request("configurationDone", nil)
+
+	if frames := c.stoppedAt("entry"); frames[0][1] != 1.0 {
+		t.Fatalf("expecting to stop on the first line, but got: %v", frames)
+	}
+
+	steps := []struct {
+		command  string
+		expected [][2]any
+	}{
+		{"next", [][2]any{{"<script>", 2.0}}},
+		{"next", [][2]any{{"<script>", 6.0}}},
+		{"stepIn", [][2]any{{"add", 3.0}, {"<script>", 6.0}}},
+		{"next", [][2]any{{"add", 4.0}, {"<script>", 6.0}}},
+		{"stepOut", [][2]any{{"<script>", 7.0}}},
+		{"next", [][2]any{{"<script>", 8.0}}},
+	}
+	for _, step := range steps {
+		c.request(step.command, map[string]any{"threadId": threadID})
+		if frames := c.stoppedAt("step"); !reflect.DeepEqual(frames, step.expected) {
+			t.Fatalf("expecting %s to reach %v, but got: %v", step.command, step.expected, frames)
+		}
+	}
+
+	c.request("continue", map[string]any{"threadId": threadID})
+	c.event("terminated")
+	c.disconnect()
+}
+
+func TestPauseAndDisconnect(t *testing.T) {
+	c, path := startSession(t, "var i = 0;\nwhile (true) {\n  i = i + 1;\n}\n")
+	c.request("launch", map[string]any{"program": path})
+	c.request("configurationDone", nil)
+
+	c.request("pause", map[string]any{"threadId": threadID})
+	if frames := c.stoppedAt("pause"); len(frames) != 1 {
+		t.Fatalf("expecting to pause the script, but got: %v", frames)
+	}
+	c.disconnect()
+}
+
+func TestRuntimeErrorsAreReported(t *testing.T) {
+	c, path := startSession(t, "print 1;\nprint -\"a\";\n")
+	c.request("launch", map[string]any{"program": path})
+	c.request("configurationDone", nil)
+
+	if body := c.event("exited"); body["exitCode"] != 70.0 {
+		t.Fatalf("expecting a runtime error exit code, but got: %v", body)
+	}
+	if !strings.HasPrefix(c.output, "1\n[line 2] Error:") || !strings.Contains(c.output, "Operand must be a number.") {
+		t.Fatalf("expecting the output and the error, but got: %q", c.output)
+	}
+	c.disconnect()
+}
@@ -0,0 +1,228 @@
+package dap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+var (
+	errNotStopped = errors.New("The script is not stopped.")
+	// errDisconnected wraps ErrExecutionCanceled so scripts can't catch it.
+	errDisconnected = fmt.Errorf("%w (the debugger disconnected)", visitor.ErrExecutionCanceled)
+)
+
+// stepMode is what the script was resumed to do, deciding where it stops
+// next besides breakpoints and pause requests.
+type stepMode int
+
+const (
+	running stepMode = iota
+	enteringScript
+	steppingIn
+	steppingOver
+	steppingOut
+)
+
+// execution is the state of the running script, guarded by Adapter.mu.
+type execution struct {
+	mode           stepMode
+	pauseRequested bool
+	terminating    bool
+
+	// Where the script stopped. The interpreter may only be inspected while
+	// it is stopped, and the variables references are only valid until then.
+	stopped     bool
+	interpreter *visitor.Interpreter
+	line        int
+	depth       int
+	references  [][]*environment.Environment
+
+	// The last statement run, to tell statements nested on its line apart.
+	previousLine     int
+	previousDepth    int
+	previousCompound bool
+}
+
+// reference hands out a variables reference for a scope.
+func (e *execution) reference(environments []*environment.Environment) int {
+	e.references = append(e.references, environments)
+	return len(e.references)
+}
+
+// stopReason tells whether the script must stop at a statement and why.
+func (e *execution) stopReason(line int, depth int, nested bool, breakpoints map[int]bool) string {
+	if e.pauseRequested {
+		e.pauseRequested = false
+		return "pause"
+	}
+	if nested {
+		return ""
+	}
+
+	switch {
+	case e.mode == enteringScript:
+		return "entry"
+	case e.mode == steppingIn:
+		return "step"
+	case e.mode == steppingOver && depth <= e.depth:
+		return "step"
+	case e.mode == steppingOut && depth < e.depth:
+		return "step"
+	case breakpoints[line]:
+		return "breakpoint"
+	}
+	return ""
+}
+
+// start runs the script once it is launched and configured.
+func (a *Adapter) start() error {
+	if !a.launched || !a.configured || a.done != nil {
+		return nil
+	}
+
+	a.done = make(chan struct{})
+	go a.run()
+	return nil
+}
+
+func (a *Adapter) run() {
+	defer close(a.done)
+
+	interpreter := visitor.CreateInterpreter()
+	interpreter.SetScriptName(a.program)
+	interpreter.SetOutput(outputWriter{adapter: a, category: "stdout"})
+	interpreter.SetHook(stepper{adapter: a})
+
+	exitCode := 0
+	for _, statement := range a.statements {
+		_, err := interpreter.Interpret(statement)
+		if err.Err == nil {
+			continue
+		}
+
+		if !errors.Is(err.Err, errDisconnected) {
+			report := fmt.Sprintf("[line %d] Error: %v\n", err.Line, err.Err)
+			if len(err.Trace) > 0 {
+				report += err.StackTrace() + "\n"
+			}
+			a.event("output", map[string]any{"category": "stderr", "output": report})
+		}
+		exitCode = err.ExitCode
+		break
+	}
+
+	a.event("exited", map[string]any{"exitCode": exitCode})
+	a.event("terminated", nil)
+}
+
+// resume lets a stopped script carry on in the given mode.
+func (a *Adapter) resume(mode stepMode) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.execution.stopped {
+		return errNotStopped
+	}
+
+	a.execution.mode = mode
+	a.execution.stopped = false
+	a.execution.interpreter = nil
+	a.execution.references = nil
+	a.resumed.Broadcast()
+	return nil
+}
+
+// pause stops the script at its next statement.
+func (a *Adapter) pause() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.execution.stopped {
+		a.execution.pauseRequested = true
+	}
+}
+
+// terminate stops the script, if it was started, and waits for it to end.
+func (a *Adapter) terminate() {
+	if a.done == nil {
+		return
+	}
+
+	a.mu.Lock()
+	a.execution.terminating = true
+	a.resumed.Broadcast()
+	a.mu.Unlock()
+
+	<-a.done
+}
+
+// stepper is the interpreter hook deciding where the script stops.
+type stepper struct {
+	adapter *Adapter
+}
+
+func (s stepper) BeforeStatement(interpreter *visitor.Interpreter, stmt core.Statement, line int) error {
+	a := s.adapter
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	e := &a.execution
+	if e.terminating {
+		return errDisconnected
+	}
+	if line == 0 {
+		return nil
+	}
+
+	// The body of `if (x) print x;` starts on the line the if statement just
+	// stopped at, so it is no new place to stop.
+	depth := interpreter.CallDepth()
+	nested := e.previousCompound && line == e.previousLine && depth == e.previousDepth
+	e.previousLine, e.previousDepth, e.previousCompound = line, depth, isCompound(stmt)
+
+	// Breakpoints are set in the program, not in the modules it imports.
+	breakpoints := a.breakpoints
+	if interpreter.CurrentScript() != a.program {
+		breakpoints = nil
+	}
+	reason := e.stopReason(line, depth, nested, breakpoints)
+	if reason == "" {
+		return nil
+	}
+
+	e.stopped, e.interpreter, e.line, e.depth = true, interpreter, line, depth
+	a.event("stopped", map[string]any{"reason": reason, "threadId": threadID, "allThreadsStopped": true})
+	for e.stopped && !e.terminating {
+		a.resumed.Wait()
+	}
+
+	if e.terminating {
+		return errDisconnected
+	}
+	return nil
+}
+
+func isCompound(stmt core.Statement) bool {
+	switch stmt.(type) {
+	case core.IfStmt, core.WhileStmt, core.ForStmt, core.TryStmt:
+		return true
+	}
+	return false
+}
+
+// outputWriter forwards what the script prints to the client.
+type outputWriter struct {
+	adapter  *Adapter
+	category string
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	if err := w.adapter.event("output", map[string]any{"category": w.category, "output": string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,319 @@
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type RoundingMode int
+
+const (
+	HalfEven RoundingMode = iota
+	HalfUp
+	HalfDown
+	Up
+	Down
+	Ceiling
+	Floor
+)
+
+var roundingNames = map[RoundingMode]string{
+	HalfEven: "half-even",
+	HalfUp:   "half-up",
+	HalfDown: "half-down",
+	Up:       "up",
+	Down:     "down",
+	Ceiling:  "ceiling",
+	Floor:    "floor",
+}
+
+func (m RoundingMode) String() string {
+	return roundingNames[m]
+}
+
+// ParseRoundingMode returns the rounding mode with the given name, as
+// written on the command line and in scripts: half-even, half-up,
+// half-down, up, down, ceiling or floor.
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	for mode, modeName := range roundingNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown rounding mode '%s'.", name)
+}
+
+// Context controls how inexact operations (currently only division) are
+// rounded. Scale is the number of digits kept after the decimal point.
+type Context struct {
+	Scale    int
+	Rounding RoundingMode
+}
+
+var DefaultContext = Context{Scale: 16, Rounding: HalfEven}
+
+// MaxScale bounds the fractional digits a Context should keep, since every
+// division builds a power of ten that large.
+const MaxScale = 1000
+
+// Decimal is an arbitrary-precision base-10 number stored as
+// unscaled * 10^-scale. Values are immutable: every operation returns a new
+// Decimal.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+func Parse(literal string) (Decimal, error) {
+	literal = strings.TrimSuffix(literal, "d")
+
+	integerPart, fractionPart, _ := strings.Cut(literal, ".")
+	unscaled, ok := new(big.Int).SetString(integerPart+fractionPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("Invalid decimal literal '%s'.", literal)
+	}
+
+	return Decimal{unscaled: unscaled, scale: len(fractionPart)}, nil
+}
+
+// FromFloat converts a number using its shortest round-tripping
+// representation, so 0.1 becomes exactly 0.1 rather than the binary
+// approximation stored in the float.
+func FromFloat(value float64) (Decimal, error) {
+	switch {
+	case math.IsNaN(value):
+		return Decimal{}, fmt.Errorf("Cannot convert NAN to a decimal.")
+	case math.IsInf(value, 1):
+		return Decimal{}, fmt.Errorf("Cannot convert INF to a decimal.")
+	case math.IsInf(value, -1):
+		return Decimal{}, fmt.Errorf("Cannot convert -INF to a decimal.")
+	}
+
+	return Parse(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+func (d Decimal) rescale(scale int) *big.Int {
+	if scale <= d.scale {
+		return d.value()
+	}
+	return new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	sum := new(big.Int).Add(d.rescale(scale), other.rescale(scale))
+	return Decimal{unscaled: sum, scale: scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d.Add(other.Neg())
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	product := new(big.Int).Mul(d.value(), other.value())
+	return Decimal{unscaled: product, scale: d.scale + other.scale}
+}
+
+// Div divides to ctx.Scale fractional digits using ctx.Rounding, then drops
+// trailing zeros that are not needed to keep the operands' own scale.
+func (d Decimal) Div(other Decimal, ctx Context) (Decimal, error) {
+	if other.value().Sign() == 0 {
+		return Decimal{}, fmt.Errorf("Division by zero.")
+	}
+
+	// d / other = (d.unscaled * 10^(ctx.Scale - d.scale + other.scale)) / other.unscaled * 10^-ctx.Scale
+	numerator := new(big.Int).Set(d.value())
+	denominator := new(big.Int).Set(other.value())
+	shift := ctx.Scale - d.scale + other.scale
+	if shift >= 0 {
+		numerator.Mul(numerator, pow10(shift))
+	} else {
+		denominator.Mul(denominator, pow10(-shift))
+	}
+
+	quotient := roundQuotient(numerator, denominator, ctx.Rounding)
+	result := Decimal{unscaled: quotient, scale: ctx.Scale}
+
+	return result.trim(max(d.scale, other.scale)), nil
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.value()), scale: d.scale}
+}
+
+// Round rounds d to scale fractional digits using mode. Digits d doesn't
+// have are not added.
+func (d Decimal) Round(scale int, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return d
+	}
+
+	rounded := roundQuotient(d.value(), pow10(d.scale-scale), mode)
+	return Decimal{unscaled: rounded, scale: scale}
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (d Decimal) IsZero() bool {
+	return d.value().Sign() == 0
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	sign := ""
+	if d.value().Sign() < 0 {
+		sign = "-"
+	}
+
+	if d.scale <= 0 {
+		return sign + digits + strings.Repeat("0", -d.scale)
+	}
+
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// trim removes trailing fractional zeros without going below minScale.
+func (d Decimal) trim(minScale int) Decimal {
+	unscaled := new(big.Int).Set(d.value())
+	scale := d.scale
+	ten := big.NewInt(10)
+	remainder := new(big.Int)
+	quotient := new(big.Int)
+
+	for scale > minScale && scale > 0 {
+		quotient.QuoRem(unscaled, ten, remainder)
+		if remainder.Sign() != 0 {
+			break
+		}
+		unscaled.Set(quotient)
+		scale--
+	}
+
+	return Decimal{unscaled: unscaled, scale: scale}
+}
+
+func roundQuotient(numerator *big.Int, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// sign of the exact result, since QuoRem truncates towards zero
+	sign := numerator.Sign() * denominator.Sign()
+
+	// compare 2*|remainder| with |denominator| to know which side of the half we are
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+	half := twiceRemainder.Cmp(new(big.Int).Abs(denominator))
+
+	awayFromZero := false
+	switch mode {
+	case Up:
+		awayFromZero = true
+	case Down:
+		awayFromZero = false
+	case Ceiling:
+		awayFromZero = sign > 0
+	case Floor:
+		awayFromZero = sign < 0
+	case HalfUp:
+		awayFromZero = half >= 0
+	case HalfDown:
+		awayFromZero = half > 0
+	case HalfEven:
+		awayFromZero = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	}
+
+	if awayFromZero {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+
+	return quotient
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// IntegerDiv returns the quotient rounded towards negative infinity.
+func (d Decimal) IntegerDiv(other Decimal) (Decimal, error) {
+	return d.Div(other, Context{Scale: 0, Rounding: Floor})
+}
+
+// Mod returns the remainder of a floored division, so the result takes the
+// sign of the divisor and d == other*d.IntegerDiv(other) + d.Mod(other).
+func (d Decimal) Mod(other Decimal) (Decimal, error) {
+	quotient, err := d.IntegerDiv(other)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	return d.Sub(other.Mul(quotient)), nil
+}
+
+// Pow raises d to an integer power. Negative powers divide using ctx.
+func (d Decimal) Pow(exponent int64, ctx Context) (Decimal, error) {
+	if exponent < 0 {
+		positive, err := d.Pow(-exponent, ctx)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return Decimal{unscaled: big.NewInt(1)}.Div(positive, ctx)
+	}
+
+	result := Decimal{unscaled: big.NewInt(1)}
+	base := d
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			result = result.Mul(base)
+		}
+		base = base.Mul(base)
+		exponent >>= 1
+	}
+
+	return result, nil
+}
+
+// Float64 returns the float nearest to d, or an infinity when d is too large
+// for a float.
+func (d Decimal) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.String(), 64)
+	return value
+}
+
+// Size returns the number of bytes taken by the digits of d.
+func (d Decimal) Size() int {
+	return (d.value().BitLen() + 7) / 8
+}
+
+// Int64 returns d as an int64 if it has no fractional part and fits.
+func (d Decimal) Int64() (int64, bool) {
+	integer := d.trim(0)
+	if integer.scale != 0 || !integer.value().IsInt64() {
+		return 0, false
+	}
+
+	return integer.value().Int64(), true
+}
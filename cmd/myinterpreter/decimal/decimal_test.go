@@ -0,0 +1,135 @@
+package decimal
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, literal string) Decimal {
+	t.Helper()
+	d, err := Parse(literal)
+	if err != nil {
+		t.Fatalf("was not expecting any errors parsing %s, but got: %v", literal, err)
+	}
+	return d
+}
+
+func TestAddHasNoFloatArtifacts(t *testing.T) {
+	sum := mustParse(t, "0.1d").Add(mustParse(t, "0.2d"))
+	if sum.String() != "0.3" {
+		t.Fatalf("expecting 0.1d + 0.2d to be 0.3, but got: %s", sum)
+	}
+	if sum.Cmp(mustParse(t, "0.30d")) != 0 {
+		t.Fatalf("expecting %s to compare equal to 0.30", sum)
+	}
+}
+
+func TestStringKeepsScale(t *testing.T) {
+	cases := map[string]string{
+		"19.99d": "19.99",
+		"0.05d":  "0.05",
+		"1.10d":  "1.10",
+		"42d":    "42",
+	}
+
+	for literal, expected := range cases {
+		if got := mustParse(t, literal).String(); got != expected {
+			t.Fatalf("expecting %s to print as %s, but got: %s", literal, expected, got)
+		}
+	}
+
+	if got := mustParse(t, "0.05d").Neg().String(); got != "-0.05" {
+		t.Fatalf("expecting -0.05, but got: %s", got)
+	}
+}
+
+func TestDivRoundingModes(t *testing.T) {
+	cases := []struct {
+		left, right string
+		mode        RoundingMode
+		expected    string
+	}{
+		{"1d", "8d", HalfEven, "0.12"},
+		{"3d", "8d", HalfEven, "0.38"},
+		{"1d", "8d", HalfUp, "0.13"},
+		{"1d", "8d", HalfDown, "0.12"},
+		{"2d", "3d", Down, "0.66"},
+		{"2d", "3d", Up, "0.67"},
+		{"-2d", "3d", Floor, "-0.67"},
+		{"-2d", "3d", Ceiling, "-0.66"},
+		{"10.00d", "4d", HalfEven, "2.50"},
+	}
+
+	for _, c := range cases {
+		quotient, err := mustParse(t, c.left).Div(mustParse(t, c.right), Context{Scale: 2, Rounding: c.mode})
+		if err != nil {
+			t.Fatalf("was not expecting any errors, but got: %v", err)
+		}
+		if quotient.String() != c.expected {
+			t.Fatalf("expecting %s / %s to be %s, but got: %s", c.left, c.right, c.expected, quotient)
+		}
+	}
+}
+
+func TestDivByZero(t *testing.T) {
+	_, err := mustParse(t, "1d").Div(mustParse(t, "0.00d"), DefaultContext)
+	if err == nil {
+		t.Fatal("was expecting a division by zero error, but didn't get one")
+	}
+}
+
+func TestFromFloatUsesShortestRepresentation(t *testing.T) {
+	d, err := FromFloat(0.1)
+	if err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err)
+	}
+	if d.String() != "0.1" {
+		t.Fatalf("expecting 0.1, but got: %s", d)
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, mode := range []RoundingMode{HalfEven, HalfUp, HalfDown, Up, Down, Ceiling, Floor} {
+		parsed, err := ParseRoundingMode(mode.String())
+		if err != nil || parsed != mode {
+			t.Fatalf("expecting %s to parse back to itself, but got: %v (%v)", mode, parsed, err)
+		}
+	}
+
+	if _, err := ParseRoundingMode("nearest"); err == nil {
+		t.Fatal("was expecting an error for an unknown rounding mode, but didn't get one")
+	}
+}
+
+func TestRound(t *testing.T) {
+	cases := []struct {
+		literal  string
+		scale    int
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.5d", 0, Floor, "2"},
+		{"-2.5d", 0, Floor, "-3"},
+		{"2.1d", 0, Ceiling, "3"},
+		{"-2.5d", 0, HalfUp, "-3"},
+		{"2.345d", 2, HalfEven, "2.34"},
+		{"19.99d", 1, Down, "19.9"},
+		{"1.5d", 3, Floor, "1.5"},
+	}
+
+	for _, c := range cases {
+		if rounded := mustParse(t, c.literal).Round(c.scale, c.mode); rounded.String() != c.expected {
+			t.Fatalf("expecting %s rounded %s to %d digits to be %s, but got: %s", c.literal, c.mode, c.scale, c.expected, rounded)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	if value := mustParse(t, "-19.99d").Float64(); value != -19.99 {
+		t.Fatalf("expecting -19.99, but got: %v", value)
+	}
+	if value := mustParse(t, "1"+strings.Repeat("0", 400)+"d").Float64(); !math.IsInf(value, 1) {
+		t.Fatalf("expecting a decimal too large for a float to be INF, but got: %v", value)
+	}
+}
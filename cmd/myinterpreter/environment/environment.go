@@ -2,21 +2,73 @@ package environment
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
 )
 
+// entrySize approximates the memory taken by one variable besides its name.
+const entrySize = 48
+
+// Allocator is charged for every variable of an environment and the value
+// it holds, and gets the bytes back when the variable is overwritten or its
+// scope ends, so embedders can cap the memory a script keeps alive.
+type Allocator interface {
+	Allocate(bytes int) error
+	Release(bytes int)
+	// Size is the number of bytes charged for holding value.
+	Size(value any) int
+}
+
 type Environment struct {
 	variables map[string]any
 	enclosing *Environment
+	allocator Allocator
+	// usage is shared by the copies of an environment.
+	usage *usage
+}
+
+// usage is the memory charged for the variables of a scope.
+type usage struct {
+	bytes int
+	// captured is set once a closure refers to the scope, which then stays
+	// alive after it ends.
+	captured bool
 }
 
 func CreateEnvironment() Environment {
-	return Environment{enclosing: nil, variables: map[string]any{}}
+	return Environment{enclosing: nil, variables: map[string]any{}, usage: &usage{}}
 }
 
+// CreateEnvironmentWithEnclosing creates a nested scope charging the same
+// allocator as its enclosing one.
 func CreateEnvironmentWithEnclosing(environment *Environment) Environment {
-	return Environment{enclosing: environment, variables: map[string]any{}}
+	return Environment{enclosing: environment, variables: map[string]any{}, allocator: environment.allocator, usage: &usage{}}
+}
+
+func (e *Environment) SetAllocator(allocator Allocator) {
+	e.allocator = allocator
+}
+
+// Enclosing returns the scope this one is nested in, or nil for the globals.
+func (e *Environment) Enclosing() *Environment {
+	return e.enclosing
+}
+
+// Names returns the variables declared directly in this scope, sorted.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.variables))
+	for name := range e.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns a variable declared directly in this scope.
+func (e *Environment) Lookup(name string) (any, bool) {
+	value, ok := e.variables[name]
+	return value, ok
 }
 
 func (e *Environment) GetVariable(token *core.Token) (any, core.Error) {
@@ -32,15 +84,29 @@ func (e *Environment) GetVariable(token *core.Token) (any, core.Error) {
 	return nil, core.Error{Line: token.Line, Err: fmt.Errorf("Undefined variable '" + token.Lexeme + "'."), ExitCode: 70}
 }
 
-func (e *Environment) AddVariable(name string, value any) {
+func (e *Environment) AddVariable(name string, value any) error {
 	if e.variables == nil {
 		e.variables = map[string]any{}
 	}
+
+	previous, exists := e.variables[name]
+	bytes := 0
+	if !exists {
+		bytes = entrySize + len(name)
+	}
+	if err := e.charge(bytes, previous, value); err != nil {
+		return err
+	}
+
 	e.variables[name] = value
+	return nil
 }
 
 func (e *Environment) AssignVariable(token *core.Token, value any) *core.Error {
-	if _, ok := e.variables[token.Lexeme]; ok {
+	if previous, ok := e.variables[token.Lexeme]; ok {
+		if err := e.charge(0, previous, value); err != nil {
+			return &core.Error{Line: token.Line, Err: err, ExitCode: 70}
+		}
 		e.variables[token.Lexeme] = value
 		return nil
 	}
@@ -52,6 +118,52 @@ func (e *Environment) AssignVariable(token *core.Token, value any) *core.Error {
 	return &core.Error{Line: token.Line, Err: fmt.Errorf("Undefined variable '" + token.Lexeme + "'."), ExitCode: 70}
 }
 
+// charge accounts for a variable changing from previous to value, plus bytes
+// for the variable itself.
+func (e *Environment) charge(bytes int, previous any, value any) error {
+	if e.allocator == nil {
+		return nil
+	}
+	if e.usage == nil {
+		e.usage = &usage{}
+	}
+
+	delta := bytes + e.allocator.Size(value) - e.allocator.Size(previous)
+	if delta > 0 {
+		if err := e.allocator.Allocate(delta); err != nil {
+			return err
+		}
+	} else {
+		e.allocator.Release(-delta)
+	}
+	e.usage.bytes += delta
+	return nil
+}
+
+// Release gives back the memory charged for the variables of a scope that
+// has ended, unless a closure still refers to it.
+func (e *Environment) Release() {
+	if e.allocator == nil || e.usage == nil || e.usage.captured {
+		return
+	}
+	e.allocator.Release(e.usage.bytes)
+	e.usage.bytes = 0
+}
+
+// Capture marks the scope, and the ones enclosing it, as kept alive by a
+// closure, so Release leaves their memory charged.
+func (e *Environment) Capture() {
+	for scope := e; scope != nil; scope = scope.enclosing {
+		if scope.usage == nil {
+			scope.usage = &usage{}
+		}
+		if scope.usage.captured {
+			return
+		}
+		scope.usage.captured = true
+	}
+}
+
 func (e Environment) String() string {
   return fmt.Sprintf("enclosing: %v, variables: %v\n", e.enclosing != nil, e.variables)
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/formatter"
+)
+
+// formatCommand implements `fmt [--check | --write] <file>...`. By default the
+// formatted source is printed; --check lists the files that need formatting
+// and exits with 1, --write rewrites them in place. `-` formats standard
+// input, which can't be rewritten.
+func formatCommand(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := flags.Bool("check", false, "report files that are not formatted instead of printing them")
+	write := flags.Bool("write", false, "rewrite files in place")
+	flags.Parse(args)
+
+	if *check && *write || *write && slices.Contains(flags.Args(), stdinName) {
+		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh fmt [--check | --write] <filename>...")
+		os.Exit(1)
+	}
+
+	unformatted := false
+	for _, filename := range flags.Args() {
+		source := readSource(filename)
+
+		formatted, errors := formatter.Format(source)
+		printErrorsAndExit(errors)
+
+		switch {
+		case *check:
+			if !bytes.Equal(source, formatted) {
+				fmt.Println(filename)
+				unformatted = true
+			}
+		case *write:
+			if bytes.Equal(source, formatted) {
+				continue
+			}
+			if err := os.WriteFile(filename, formatted, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+				os.Exit(1)
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	if unformatted {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,401 @@
+package formatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+const indentation = "  "
+
+// Format reprints Lox source in the canonical style: one statement per line,
+// two space indentation, spaces around binary operators and braces on the
+// line of the statement they belong to. Comments are kept, and so is a single
+// blank line wherever the source separated statements with blank lines.
+func Format(source []byte) ([]byte, []core.Error) {
+	tokens, comments, errs := scanner.ScanFileWithComments(source)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	statements, err := parser.Parse(tokens)
+	if err != nil {
+		return nil, []core.Error{*err}
+	}
+
+	f := formatter{comments: comments, source: strings.Split(string(source), "\n")}
+	// Every brace in Lox outside the name list of an import delimits a
+	// block, so the blocks of the syntax tree open and close in the same
+	// order as those brace tokens appear.
+	inImport := false
+	for _, token := range tokens {
+		switch token.Type {
+		case core.IMPORT:
+			inImport = true
+		case core.SEMICOLON:
+			inImport = false
+		case core.LEFT_BRACE:
+			if !inImport {
+				f.openings = append(f.openings, token.Line)
+			}
+		case core.RIGHT_BRACE:
+			if !inImport {
+				f.closings = append(f.closings, token.Line)
+			}
+		}
+	}
+
+	for _, statement := range statements {
+		f.statement(statement)
+	}
+	f.flushComments(-1)
+
+	if len(f.lines) == 0 {
+		return []byte{}, nil
+	}
+	return []byte(strings.Join(f.lines, "\n") + "\n"), nil
+}
+
+// formatter prints statements line by line. It implements both visitors:
+// statements are written to lines, expressions are returned as strings.
+type formatter struct {
+	lines    []string
+	indent   int
+	comments []core.Comment
+	source   []string
+	// openings and closings hold the lines of the braces not yet printed.
+	openings []int
+	closings []int
+	// atBlockStart is set after printing `{`, where blank lines are dropped.
+	atBlockStart bool
+}
+
+// line starts a new output line at the current indentation.
+func (f *formatter) line(text string) {
+	f.lines = append(f.lines, strings.Repeat(indentation, f.indent)+text)
+	f.atBlockStart = false
+}
+
+// join continues the last output line, as in `} else {`.
+func (f *formatter) join(text string) {
+	f.lines[len(f.lines)-1] += " " + text
+	f.atBlockStart = false
+}
+
+// separate keeps one blank line before source line if the source had one.
+func (f *formatter) separate(line int) {
+	if f.atBlockStart || len(f.lines) == 0 || f.lines[len(f.lines)-1] == "" {
+		return
+	}
+
+	if line >= 2 && line-2 < len(f.source) && strings.TrimSpace(f.source[line-2]) == "" {
+		f.lines = append(f.lines, "")
+	}
+}
+
+// flushComments prints the comments found before line, or all of them if
+// line is negative. Trailing comments stay at the end of the line they
+// followed.
+func (f *formatter) flushComments(line int) {
+	for len(f.comments) > 0 && (line < 0 || f.comments[0].Line < line) {
+		comment := f.comments[0]
+		f.comments = f.comments[1:]
+
+		if comment.Trailing && len(f.lines) > 0 && f.lines[len(f.lines)-1] != "" {
+			f.lines[len(f.lines)-1] += " " + comment.Text
+			continue
+		}
+
+		f.separate(comment.Line)
+		f.line(comment.Text)
+	}
+}
+
+func (f *formatter) statement(stmt core.Statement) {
+	line := f.startLine(stmt)
+	if line > 0 {
+		f.flushComments(line)
+		f.separate(line)
+	}
+
+	stmt.Accept(f)
+}
+
+// block prints the statements between a pair of braces, the opening one
+// having been printed already, followed by the closing brace.
+func (f *formatter) block(statements []core.Statement) {
+	f.openings = f.openings[1:]
+	f.indent++
+	f.atBlockStart = true
+
+	for _, statement := range statements {
+		f.statement(statement)
+	}
+
+	f.flushComments(f.closings[0])
+	f.closings = f.closings[1:]
+	f.indent--
+	f.line("}")
+}
+
+// clause prints a header such as `while (x)` followed by its body, which
+// shares the header line when it is a block and is indented below it
+// otherwise.
+func (f *formatter) clause(header string, join bool, body core.Statement) {
+	block, isBlock := body.(core.BlockStmt)
+	if isBlock {
+		header += " {"
+	}
+
+	if join {
+		f.join(header)
+	} else {
+		f.line(header)
+	}
+
+	if isBlock {
+		f.block(block.Statements)
+		return
+	}
+
+	f.indent++
+	f.statement(body)
+	f.indent--
+}
+
+// Expression formats a single expression in the canonical style.
+func Expression(expr core.Expression) string {
+	f := formatter{}
+	return f.expression(expr)
+}
+
+func (f *formatter) expression(expr core.Expression) string {
+	str, _ := expr.Accept(f)
+	return str.(string)
+}
+
+// inline formats the initializer clause of a for loop, without its semicolon.
+func (f *formatter) inline(stmt core.Statement) string {
+	switch node := stmt.(type) {
+	case core.VarStmt:
+		if node.Initializer == nil {
+			return "var " + node.Name.Lexeme
+		}
+		return "var " + node.Name.Lexeme + " = " + f.expression(node.Initializer)
+	case core.ExpressionStmt:
+		return f.expression(node.Expr)
+	}
+
+	return ""
+}
+
+// startLine is the source line a statement begins on, or 0 when the syntax
+// tree doesn't tell.
+func (f *formatter) startLine(stmt core.Statement) int {
+	if _, ok := stmt.(core.BlockStmt); ok {
+		return f.openings[0]
+	}
+
+	return core.StatementLine(stmt)
+}
+
+func (f *formatter) VisitExpressionStmt(stmt core.ExpressionStmt) (any, core.Error) {
+	f.line(f.expression(stmt.Expr) + ";")
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitPrintStmt(stmt core.PrintStmt) (any, core.Error) {
+	f.line("print " + f.expression(stmt.Expr) + ";")
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitVarStmt(stmt core.VarStmt) (any, core.Error) {
+	f.line(f.inline(stmt) + ";")
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitBlockStmt(stmt core.BlockStmt) (any, core.Error) {
+	f.line("{")
+	f.block(stmt.Statements)
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitFunctionStmt(stmt core.FunctionStmt) (any, core.Error) {
+	params := []string{}
+	for _, param := range stmt.Params {
+		params = append(params, param.Lexeme)
+	}
+
+	f.line(fmt.Sprintf("fun %s(%s) {", stmt.Name.Lexeme, strings.Join(params, ", ")))
+	f.block(stmt.Body)
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitReturnStmt(stmt core.ReturnStmt) (any, core.Error) {
+	if stmt.Value == nil {
+		f.line("return;")
+	} else {
+		f.line("return " + f.expression(stmt.Value) + ";")
+	}
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitThrowStmt(stmt core.ThrowStmt) (any, core.Error) {
+	f.line("throw " + f.expression(stmt.Value) + ";")
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitTryStmt(stmt core.TryStmt) (any, core.Error) {
+	f.line("try {")
+	f.block(stmt.Body)
+
+	if stmt.HasCatch {
+		f.join(fmt.Sprintf("catch (%s) {", stmt.CatchName.Lexeme))
+		f.block(stmt.CatchBody)
+	}
+
+	if stmt.FinallyBody != nil {
+		f.join("finally {")
+		f.block(stmt.FinallyBody)
+	}
+
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitIfStmt(stmt core.IfStmt) (any, core.Error) {
+	f.ifStatement(stmt, "if", false)
+	return nil, core.Error{}
+}
+
+// ifStatement prints an if statement, chaining `else if` on the line that
+// closes the previous branch when it ends with a brace.
+func (f *formatter) ifStatement(stmt core.IfStmt, keyword string, join bool) {
+	f.clause(keyword+" ("+f.expression(stmt.Condition)+")", join, stmt.ThenBranch)
+	if stmt.ElseBranch == nil {
+		return
+	}
+
+	_, thenIsBlock := stmt.ThenBranch.(core.BlockStmt)
+	if elseIf, ok := stmt.ElseBranch.(core.IfStmt); ok {
+		f.ifStatement(elseIf, "else if", thenIsBlock)
+		return
+	}
+
+	f.clause("else", thenIsBlock, stmt.ElseBranch)
+}
+
+func (f *formatter) VisitWhileStmt(stmt core.WhileStmt) (any, core.Error) {
+	f.clause("while ("+f.expression(stmt.Condition)+")", false, stmt.Body)
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitForStmt(stmt core.ForStmt) (any, core.Error) {
+	header := "for (" + f.inline(stmt.Initializer) + ";"
+	if stmt.Condition != nil {
+		header += " " + f.expression(stmt.Condition)
+	}
+	header += ";"
+	if stmt.Increment != nil {
+		header += " " + f.expression(stmt.Increment)
+	}
+
+	f.clause(header+")", false, stmt.Body)
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitImportStmt(stmt core.ImportStmt) (any, core.Error) {
+	if stmt.Names == nil {
+		f.line(fmt.Sprintf("import %s as %s;", stmt.Path.Lexeme, stmt.Alias.Lexeme))
+		return nil, core.Error{}
+	}
+
+	names := []string{}
+	for _, name := range stmt.Names {
+		if name.Alias.Lexeme == name.Name.Lexeme {
+			names = append(names, name.Name.Lexeme)
+		} else {
+			names = append(names, name.Name.Lexeme+" as "+name.Alias.Lexeme)
+		}
+	}
+	f.line(fmt.Sprintf("import { %s } from %s;", strings.Join(names, ", "), stmt.Path.Lexeme))
+	return nil, core.Error{}
+}
+
+func (f *formatter) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
+	return f.expression(expr.Left) + " " + expr.Operator.Lexeme + " " + f.expression(expr.Right), core.Error{}
+}
+
+func (f *formatter) VisitLogicalExpr(expr core.Logical) (any, core.Error) {
+	return f.expression(expr.Left) + " " + expr.Operator.Lexeme + " " + f.expression(expr.Right), core.Error{}
+}
+
+func (f *formatter) VisitGroupExpr(expr core.Grouping) (any, core.Error) {
+	return "(" + f.expression(expr.Expr) + ")", core.Error{}
+}
+
+func (f *formatter) VisitLiteralExpr(expr core.Literal) (any, core.Error) {
+	switch value := expr.Value.(type) {
+	case nil:
+		return "nil", core.Error{}
+	case string:
+		return `"` + value + `"`, core.Error{}
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), core.Error{}
+	case decimal.Decimal:
+		return value.String() + "d", core.Error{}
+	}
+
+	return fmt.Sprint(expr.Value), core.Error{}
+}
+
+func (f *formatter) VisitUnaryExpr(expr core.Unary) (any, core.Error) {
+	right := f.expression(expr.Right)
+	// `- -x` must not be printed as the decrement operator.
+	if expr.Operator.Type == core.MINUS && strings.HasPrefix(right, "-") {
+		return "- " + right, core.Error{}
+	}
+
+	return expr.Operator.Lexeme + right, core.Error{}
+}
+
+func (f *formatter) VisitVariableExpr(expr core.Variable) (any, core.Error) {
+	return expr.Name.Lexeme, core.Error{}
+}
+
+func (f *formatter) VisitAssignExpr(expr core.Assign) (any, core.Error) {
+	return expr.Name.Lexeme + " = " + f.expression(expr.Value), core.Error{}
+}
+
+func (f *formatter) VisitCompoundAssignExpr(expr core.CompoundAssign) (any, core.Error) {
+	return expr.Name.Lexeme + " " + expr.Operator.Lexeme + " " + f.expression(expr.Value), core.Error{}
+}
+
+func (f *formatter) VisitIncrementExpr(expr core.Increment) (any, core.Error) {
+	if expr.Prefix {
+		return expr.Operator.Lexeme + expr.Name.Lexeme, core.Error{}
+	}
+	return expr.Name.Lexeme + expr.Operator.Lexeme, core.Error{}
+}
+
+func (f *formatter) VisitConditionalExpr(expr core.Conditional) (any, core.Error) {
+	str := fmt.Sprintf("%s ? %s : %s", f.expression(expr.Condition), f.expression(expr.Then), f.expression(expr.Else))
+	return str, core.Error{}
+}
+
+func (f *formatter) VisitCallExpr(expr core.Call) (any, core.Error) {
+	arguments := []string{}
+	for _, argument := range expr.Arguments {
+		arguments = append(arguments, f.expression(argument))
+	}
+
+	return f.expression(expr.Callee) + "(" + strings.Join(arguments, ", ") + ")", core.Error{}
+}
+
+func (f *formatter) VisitGetExpr(expr core.Get) (any, core.Error) {
+	return f.expression(expr.Object) + "." + expr.Name.Lexeme, core.Error{}
+}
@@ -0,0 +1,132 @@
+package formatter
+
+import "testing"
+
+func format(t *testing.T, source string) string {
+	t.Helper()
+
+	formatted, errs := Format([]byte(source))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+	return string(formatted)
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			"spacing around operators",
+			"var x=1+2*-3;print x==-9;x+=1;x++;--x;",
+			"var x = 1 + 2 * -3;\nprint x == -9;\nx += 1;\nx++;\n--x;\n",
+		},
+		{
+			"literals",
+			`print 1.50; print 12.30d; print "a  b"; print nil; print true;`,
+			"print 1.5;\nprint 12.30d;\nprint \"a  b\";\nprint nil;\nprint true;\n",
+		},
+		{
+			"groupings and calls",
+			"print (a+b)*c; f(1,g(2) ,x.y); print c?a:b??d;",
+			"print (a + b) * c;\nf(1, g(2), x.y);\nprint c ? a : b ?? d;\n",
+		},
+		{
+			"integer division and bitwise operators",
+			"print 7~/2%3; print ~a&b|c<<1; // note",
+			"print 7 ~/ 2 % 3;\nprint ~a & b | c << 1; // note\n",
+		},
+		{
+			"nested negation",
+			"print - -x; print -(-x); print - --x;",
+			"print - -x;\nprint -(-x);\nprint - --x;\n",
+		},
+		{
+			"blocks and functions",
+			"fun add(a,b){return a+b;} {var x;{}}",
+			"fun add(a, b) {\n  return a + b;\n}\n{\n  var x;\n  {\n  }\n}\n",
+		},
+		{
+			"control flow",
+			"if(a)print 1;else if(b){print 2;}else print 3;\nwhile(x<3)x++;\nfor(var i=0;i<3;i++){print i;}\nfor(;;)print 1;",
+			"if (a)\n  print 1;\nelse if (b) {\n  print 2;\n} else\n  print 3;\nwhile (x < 3)\n  x++;\nfor (var i = 0; i < 3; i++) {\n  print i;\n}\nfor (;;)\n  print 1;\n",
+		},
+		{
+			"try catch finally",
+			"try{throw Error(\"x\");}catch(e){print e.message;}finally{print 1;}",
+			"try {\n  throw Error(\"x\");\n} catch (e) {\n  print e.message;\n} finally {\n  print 1;\n}\n",
+		},
+		{
+			"imports",
+			"import \"lib.lox\"as lib;import{a,b as c}from \"x.lox\";{print a;}",
+			"import \"lib.lox\" as lib;\nimport { a, b as c } from \"x.lox\";\n{\n  print a;\n}\n",
+		},
+		{
+			"blank lines",
+			"var a;\n\n\n\nvar b;\nvar c;\n{\n\n  print a;\n\n}\n",
+			"var a;\n\nvar b;\nvar c;\n{\n  print a;\n}\n",
+		},
+	}
+
+	for _, c := range cases {
+		if got := format(t, c.source); got != c.expected {
+			t.Fatalf("%s: expecting\n%s\nbut got:\n%s", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestFormatKeepsComments(t *testing.T) {
+	source := `// header comment
+
+var a = 1; // trailing
+fun f() { // opens the body
+    // inside
+    return a;
+    // before the closing brace
+}
+if (a) {
+  print a;
+} // after the block
+// at the end
+`
+	expected := `// header comment
+
+var a = 1; // trailing
+fun f() { // opens the body
+  // inside
+  return a;
+  // before the closing brace
+}
+if (a) {
+  print a;
+} // after the block
+// at the end
+`
+	if got := format(t, source); got != expected {
+		t.Fatalf("expecting\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestFormatIsIdempotent(t *testing.T) {
+	source := `fun fib(n){if(n<2)return n; // base case
+return fib(n-1)+fib(n-2);}
+
+// print a few
+for(var i=0;i<10;i=i+1){print fib(i);}
+try { risky(); } catch (e) { print e; } // ignore
+`
+	once := format(t, source)
+	twice := format(t, once)
+	if once != twice {
+		t.Fatalf("expecting formatting to be stable, but got:\n%s\nthen:\n%s", once, twice)
+	}
+}
+
+func TestFormatReportsParseErrors(t *testing.T) {
+	_, errs := Format([]byte("var = 1;"))
+	if len(errs) != 1 || errs[0].ExitCode != 65 {
+		t.Fatalf("expecting a parse error, but got: %v", errs)
+	}
+}
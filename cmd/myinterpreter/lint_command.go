@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/linter"
+)
+
+type lintReport struct {
+	File string `json:"file"`
+	linter.Diagnostic
+}
+
+// lintCommand implements `lint [--format=human|json] [--disable=rule,...]
+// <file>...`. It exits with 1 when any diagnostic is reported.
+func lintCommand(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := flags.String("format", "human", "output format: human or json")
+	disable := flags.String("disable", "", "comma separated rule IDs to skip")
+	flags.Parse(args)
+
+	config := linter.Config{Disabled: map[string]bool{}}
+	for _, rule := range strings.Split(*disable, ",") {
+		if rule == "" {
+			continue
+		}
+		if _, ok := linter.Rules[rule]; !ok {
+			fmt.Fprintf(os.Stderr, "Unknown lint rule: %s\n", rule)
+			os.Exit(1)
+		}
+		config.Disabled[rule] = true
+	}
+
+	if *format != "human" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", *format)
+		os.Exit(1)
+	}
+
+	reports := []lintReport{}
+	for _, filename := range flags.Args() {
+		source := readSource(filename)
+
+		diagnostics, errors := linter.Lint(source, config)
+		printErrorsAndExit(errors)
+
+		for _, diagnostic := range diagnostics {
+			reports = append(reports, lintReport{File: filename, Diagnostic: diagnostic})
+		}
+	}
+
+	if *format == "json" {
+		output, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Println(string(output))
+	} else {
+		for _, report := range reports {
+			fmt.Printf("%s:%d: %s: %s [%s]\n", report.File, report.Line, report.Severity, report.Message, report.Rule)
+		}
+	}
+
+	if len(reports) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,445 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// checker walks the syntax tree once, resolving names through a stack of
+// scopes the way the interpreter's environments would nest at runtime.
+type checker struct {
+	scopes      []*scope
+	diagnostics []Diagnostic
+	// line is the line of the statement being checked, reported for
+	// problems found in expressions without tokens of their own.
+	line int
+}
+
+type scope struct {
+	global   bool
+	bindings map[string]*binding
+	order    []string
+}
+
+type binding struct {
+	name core.Token
+	used bool
+	// checked is false for parameters and catch variables, which are never
+	// reported as unused or shadowing.
+	checked bool
+}
+
+// createChecker starts with the global scope holding the natives and every
+// top-level declaration, since functions may refer to globals declared
+// further down the script.
+func createChecker(statements []core.Statement) *checker {
+	globals := &scope{global: true, bindings: map[string]*binding{}}
+	for _, name := range visitor.NativeNames() {
+		globals.bindings[name] = &binding{name: core.Token{Lexeme: name}}
+	}
+
+	for _, statement := range statements {
+		switch node := statement.(type) {
+		case core.VarStmt:
+			globals.bindings[node.Name.Lexeme] = &binding{name: node.Name}
+		case core.FunctionStmt:
+			globals.bindings[node.Name.Lexeme] = &binding{name: node.Name}
+		case core.ImportStmt:
+			for _, name := range node.Bindings() {
+				globals.bindings[name.Lexeme] = &binding{name: name}
+			}
+		}
+	}
+
+	return &checker{scopes: []*scope{globals}}
+}
+
+func (c *checker) report(rule string, line int, message string) {
+	if line == 0 {
+		line = c.line
+	}
+
+	c.diagnostics = append(c.diagnostics, Diagnostic{Rule: rule, Severity: Rules[rule], Line: line, Message: message})
+}
+
+func (c *checker) beginScope() {
+	c.scopes = append(c.scopes, &scope{bindings: map[string]*binding{}})
+}
+
+// endScope pops the innermost scope, reporting the local variables that
+// were never read.
+func (c *checker) endScope() {
+	current := c.scopes[len(c.scopes)-1]
+	c.scopes = c.scopes[:len(c.scopes)-1]
+
+	for _, name := range current.order {
+		binding := current.bindings[name]
+		if binding.checked && !binding.used {
+			c.report(UnusedVariable, binding.name.Line, fmt.Sprintf("Variable '%s' is declared but never used.", name))
+		}
+	}
+}
+
+func (c *checker) declare(name core.Token, checked bool) {
+	current := c.scopes[len(c.scopes)-1]
+	if current.global {
+		// Already bound by createChecker, possibly used by a function above.
+		return
+	}
+
+	if checked {
+		for index := len(c.scopes) - 2; index >= 0; index-- {
+			shadowed, ok := c.scopes[index].bindings[name.Lexeme]
+			if ok && shadowed.name.Line <= name.Line {
+				message := fmt.Sprintf("Variable '%s' shadows a variable from an enclosing scope.", name.Lexeme)
+				if shadowed.name.Line > 0 {
+					message = fmt.Sprintf("Variable '%s' shadows the one declared on line %d.", name.Lexeme, shadowed.name.Line)
+				}
+				c.report(ShadowedVariable, name.Line, message)
+				break
+			}
+		}
+	}
+
+	if _, redeclared := current.bindings[name.Lexeme]; !redeclared {
+		current.order = append(current.order, name.Lexeme)
+	}
+	current.bindings[name.Lexeme] = &binding{name: name, checked: checked}
+}
+
+func (c *checker) resolve(name string) *binding {
+	for index := len(c.scopes) - 1; index >= 0; index-- {
+		if binding, ok := c.scopes[index].bindings[name]; ok {
+			return binding
+		}
+	}
+	return nil
+}
+
+// statements checks a list of statements sharing a scope and reports the
+// first one that can never run.
+func (c *checker) statements(statements []core.Statement) {
+	terminated, reported := false, false
+	for _, statement := range statements {
+		if terminated && !reported {
+			c.report(UnreachableCode, core.StatementLine(statement), "Unreachable code.")
+			reported = true
+		}
+
+		c.check(statement)
+		terminated = terminated || terminates(statement)
+	}
+}
+
+func (c *checker) check(statement core.Statement) {
+	if line := core.StatementLine(statement); line > 0 {
+		c.line = line
+	}
+	statement.Accept(c)
+}
+
+func (c *checker) expression(expr core.Expression) {
+	if expr != nil {
+		expr.Accept(c)
+	}
+}
+
+// condition checks the condition of a branch or loop. `while (true)` is the
+// usual way to write an endless loop, so loops may use a literal true.
+func (c *checker) condition(expr core.Expression, line int, loop bool) {
+	c.expression(expr)
+
+	if literal, ok := expr.(core.Literal); ok && loop && literal.Value == true {
+		return
+	}
+	if !isConstant(expr) {
+		return
+	}
+
+	evaluator := visitor.CreateEvaluator()
+	value, err := evaluator.Evaluate(expr)
+	if err.Err != nil {
+		return
+	}
+
+	if value == nil || value == false {
+		c.report(ConstantCondition, line, "Condition is always false.")
+	} else {
+		c.report(ConstantCondition, line, "Condition is always true.")
+	}
+}
+
+func (c *checker) VisitExpressionStmt(stmt core.ExpressionStmt) (any, core.Error) {
+	c.expression(stmt.Expr)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitPrintStmt(stmt core.PrintStmt) (any, core.Error) {
+	c.expression(stmt.Expr)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitVarStmt(stmt core.VarStmt) (any, core.Error) {
+	c.expression(stmt.Initializer)
+	c.declare(stmt.Name, true)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitBlockStmt(stmt core.BlockStmt) (any, core.Error) {
+	c.beginScope()
+	c.statements(stmt.Statements)
+	c.endScope()
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitFunctionStmt(stmt core.FunctionStmt) (any, core.Error) {
+	c.declare(stmt.Name, true)
+
+	c.beginScope()
+	for _, param := range stmt.Params {
+		c.declare(param, false)
+	}
+	c.statements(stmt.Body)
+	c.endScope()
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitReturnStmt(stmt core.ReturnStmt) (any, core.Error) {
+	c.expression(stmt.Value)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitThrowStmt(stmt core.ThrowStmt) (any, core.Error) {
+	c.expression(stmt.Value)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitTryStmt(stmt core.TryStmt) (any, core.Error) {
+	c.beginScope()
+	c.statements(stmt.Body)
+	c.endScope()
+
+	if stmt.HasCatch {
+		c.beginScope()
+		c.declare(stmt.CatchName, false)
+		c.statements(stmt.CatchBody)
+		c.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		c.beginScope()
+		c.statements(stmt.FinallyBody)
+		c.endScope()
+	}
+
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitIfStmt(stmt core.IfStmt) (any, core.Error) {
+	c.condition(stmt.Condition, stmt.Keyword.Line, false)
+	c.check(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		c.check(stmt.ElseBranch)
+	}
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitWhileStmt(stmt core.WhileStmt) (any, core.Error) {
+	c.condition(stmt.Condition, stmt.Keyword.Line, true)
+	c.check(stmt.Body)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitForStmt(stmt core.ForStmt) (any, core.Error) {
+	c.beginScope()
+	if stmt.Initializer != nil {
+		c.check(stmt.Initializer)
+	}
+	if stmt.Condition != nil {
+		c.condition(stmt.Condition, stmt.Keyword.Line, true)
+	}
+	c.expression(stmt.Increment)
+	c.check(stmt.Body)
+	c.endScope()
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitImportStmt(stmt core.ImportStmt) (any, core.Error) {
+	for _, name := range stmt.Bindings() {
+		c.declare(name, true)
+	}
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
+	c.expression(expr.Left)
+	c.expression(expr.Right)
+
+	switch expr.Operator.Type {
+	case core.EQUAL_EQUAL, core.BANG_EQUAL, core.LESS, core.LESS_EQUAL, core.GREATER, core.GREATER_EQUAL:
+	default:
+		return nil, core.Error{}
+	}
+
+	if !isPure(expr.Left) || isConstant(expr.Left) {
+		return nil, core.Error{}
+	}
+
+	left, _ := expr.Left.Accept(visitor.StringifyVisitor{})
+	right, _ := expr.Right.Accept(visitor.StringifyVisitor{})
+	if left == right {
+		c.report(SelfComparison, expr.Operator.Line, fmt.Sprintf("Comparing an expression with itself using '%s'.", expr.Operator.Lexeme))
+	}
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitGroupExpr(expr core.Grouping) (any, core.Error) {
+	c.expression(expr.Expr)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitLiteralExpr(expr core.Literal) (any, core.Error) {
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitUnaryExpr(expr core.Unary) (any, core.Error) {
+	c.expression(expr.Right)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitVariableExpr(expr core.Variable) (any, core.Error) {
+	if binding := c.resolve(expr.Name.Lexeme); binding != nil {
+		binding.used = true
+	}
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitAssignExpr(expr core.Assign) (any, core.Error) {
+	c.expression(expr.Value)
+	c.assign(expr.Name, false)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitCompoundAssignExpr(expr core.CompoundAssign) (any, core.Error) {
+	c.expression(expr.Value)
+	c.assign(expr.Name, true)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitIncrementExpr(expr core.Increment) (any, core.Error) {
+	c.assign(expr.Name, true)
+	return nil, core.Error{}
+}
+
+// assign resolves the target of an assignment. Only operators that read the
+// current value count as a use of the variable.
+func (c *checker) assign(name core.Token, reads bool) {
+	binding := c.resolve(name.Lexeme)
+	if binding == nil {
+		c.report(UndeclaredAssignment, name.Line, fmt.Sprintf("Assignment to undeclared variable '%s'.", name.Lexeme))
+		return
+	}
+
+	if reads {
+		binding.used = true
+	}
+}
+
+func (c *checker) VisitConditionalExpr(expr core.Conditional) (any, core.Error) {
+	c.condition(expr.Condition, core.ExpressionLine(expr.Condition), false)
+	c.expression(expr.Then)
+	c.expression(expr.Else)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitLogicalExpr(expr core.Logical) (any, core.Error) {
+	c.expression(expr.Left)
+	c.expression(expr.Right)
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitCallExpr(expr core.Call) (any, core.Error) {
+	c.expression(expr.Callee)
+	for _, argument := range expr.Arguments {
+		c.expression(argument)
+	}
+	return nil, core.Error{}
+}
+
+func (c *checker) VisitGetExpr(expr core.Get) (any, core.Error) {
+	c.expression(expr.Object)
+	return nil, core.Error{}
+}
+
+// terminates tells whether control never continues past stmt.
+func terminates(stmt core.Statement) bool {
+	switch node := stmt.(type) {
+	case core.ReturnStmt, core.ThrowStmt:
+		return true
+	case core.BlockStmt:
+		return anyTerminates(node.Statements)
+	case core.IfStmt:
+		return node.ElseBranch != nil && terminates(node.ThenBranch) && terminates(node.ElseBranch)
+	case core.TryStmt:
+		if node.FinallyBody != nil && anyTerminates(node.FinallyBody) {
+			return true
+		}
+		return anyTerminates(node.Body) && (!node.HasCatch || anyTerminates(node.CatchBody))
+	}
+
+	return false
+}
+
+func anyTerminates(statements []core.Statement) bool {
+	for _, statement := range statements {
+		if terminates(statement) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConstant tells whether expr is built from literals only.
+func isConstant(expr core.Expression) bool {
+	switch node := expr.(type) {
+	case core.Literal:
+		return true
+	case core.Grouping:
+		return isConstant(node.Expr)
+	case core.Unary:
+		return isConstant(node.Right)
+	case core.Binary:
+		return isConstant(node.Left) && isConstant(node.Right)
+	case core.Logical:
+		return isConstant(node.Left) && isConstant(node.Right)
+	case core.Conditional:
+		return isConstant(node.Condition) && isConstant(node.Then) && isConstant(node.Else)
+	}
+
+	return false
+}
+
+// isPure tells whether evaluating expr twice gives the same value, that is,
+// whether it has no calls or assignments.
+func isPure(expr core.Expression) bool {
+	switch node := expr.(type) {
+	case core.Literal, core.Variable:
+		return true
+	case core.Grouping:
+		return isPure(node.Expr)
+	case core.Unary:
+		return isPure(node.Right)
+	case core.Binary:
+		return isPure(node.Left) && isPure(node.Right)
+	case core.Logical:
+		return isPure(node.Left) && isPure(node.Right)
+	case core.Conditional:
+		return isPure(node.Condition) && isPure(node.Then) && isPure(node.Else)
+	case core.Get:
+		return isPure(node.Object)
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package linter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+type Severity string
+
+const (
+	Warning Severity = "warning"
+	Error   Severity = "error"
+)
+
+// Rule IDs, as used in reports, in Config.Disabled and in disable comments.
+const (
+	UnusedVariable       = "unused-variable"
+	ShadowedVariable     = "shadowed-variable"
+	UndeclaredAssignment = "undeclared-assignment"
+	SelfComparison       = "self-comparison"
+	ConstantCondition    = "constant-condition"
+	UnreachableCode      = "unreachable-code"
+)
+
+// Rules maps every rule ID to the severity of its diagnostics.
+var Rules = map[string]Severity{
+	UnusedVariable:       Warning,
+	ShadowedVariable:     Warning,
+	UndeclaredAssignment: Error,
+	SelfComparison:       Warning,
+	ConstantCondition:    Warning,
+	UnreachableCode:      Warning,
+}
+
+type Diagnostic struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+}
+
+// Config selects the rules to run. All rules are enabled by default.
+type Config struct {
+	Disabled map[string]bool
+}
+
+// Lint parses source and reports problems found by the enabled rules, sorted
+// by line. A comment `// lint:disable-line rule-a, rule-b` silences the
+// listed rules on its own line and `// lint:disable-next-line` on the line
+// below; without rule IDs they silence every rule.
+func Lint(source []byte, config Config) ([]Diagnostic, []core.Error) {
+	tokens, comments, errs := scanner.ScanFileWithComments(source)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	statements, err := parser.Parse(tokens)
+	if err != nil {
+		return nil, []core.Error{*err}
+	}
+
+	c := createChecker(statements)
+	c.statements(statements)
+	c.endScope()
+
+	suppressed := suppressions(comments)
+	diagnostics := []Diagnostic{}
+	for _, diagnostic := range c.diagnostics {
+		if config.Disabled[diagnostic.Rule] || isSuppressed(suppressed[diagnostic.Line], diagnostic.Rule) {
+			continue
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+	return diagnostics, nil
+}
+
+// suppressions maps lines to the rules disabled on them. An empty list
+// disables every rule.
+func suppressions(comments []core.Comment) map[int][]string {
+	suppressed := map[int][]string{}
+	for _, comment := range comments {
+		fields := strings.Fields(strings.TrimPrefix(comment.Text, "//"))
+		if len(fields) == 0 {
+			continue
+		}
+
+		line := comment.Line
+		switch fields[0] {
+		case "lint:disable-line":
+		case "lint:disable-next-line":
+			line++
+		default:
+			continue
+		}
+
+		rules := []string{}
+		for _, field := range fields[1:] {
+			for _, rule := range strings.Split(field, ",") {
+				if rule != "" {
+					rules = append(rules, rule)
+				}
+			}
+		}
+		suppressed[line] = rules
+	}
+
+	return suppressed
+}
+
+func isSuppressed(rules []string, rule string) bool {
+	if rules == nil {
+		return false
+	}
+	if len(rules) == 0 {
+		return true
+	}
+
+	for _, suppressed := range rules {
+		if suppressed == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package linter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// lint returns the rule and line of every diagnostic reported for source.
+func lint(t *testing.T, source string, config Config) [][2]any {
+	t.Helper()
+
+	diagnostics, errs := Lint([]byte(source), config)
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+
+	found := [][2]any{}
+	for _, diagnostic := range diagnostics {
+		found = append(found, [2]any{diagnostic.Rule, diagnostic.Line})
+	}
+	return found
+}
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected [][2]any
+	}{
+		{
+			"unused local",
+			"fun f() {\n  var a = 1;\n  var b = 2;\n  print b;\n}\nf();",
+			[][2]any{{UnusedVariable, 2}},
+		},
+		{
+			"globals and parameters are never unused",
+			"var a = 1;\nfun f(x) {}\nf(1);",
+			[][2]any{},
+		},
+		{
+			"assignment alone is not a use",
+			"{\n  var a;\n  a = 1;\n}",
+			[][2]any{{UnusedVariable, 2}},
+		},
+		{
+			"shadowed variable",
+			"var a = 1;\n{\n  var b = a;\n  {\n    var b = 2;\n    print b;\n  }\n  print b;\n}",
+			[][2]any{{ShadowedVariable, 5}},
+		},
+		{
+			"shadowed global",
+			"var a = 1;\n{\n  var a = 2;\n  print a;\n}",
+			[][2]any{{ShadowedVariable, 3}},
+		},
+		{
+			"undeclared assignment",
+			"fun f() {\n  total = 1;\n  count += 1;\n  later = 2;\n}\nvar later;",
+			[][2]any{{UndeclaredAssignment, 2}, {UndeclaredAssignment, 3}},
+		},
+		{
+			"self comparison",
+			"var x = 1;\nprint x == x;\nprint x.y < x.y;\nprint f() == f();\nprint x == (x);",
+			[][2]any{{SelfComparison, 2}, {SelfComparison, 3}},
+		},
+		{
+			"constant conditions",
+			"if (1 < 2) print 1;\nwhile (false) {}\nwhile (true) {}\nvar x = nil ? 1 : 2;\nif (x) print x;",
+			[][2]any{{ConstantCondition, 1}, {ConstantCondition, 2}, {ConstantCondition, 4}},
+		},
+		{
+			"integer division next to comments",
+			"var a = 1; // a // b\nif (4 ~/ 2 > 1) print a ~/ 2; // print a\n",
+			[][2]any{{ConstantCondition, 2}},
+		},
+		{
+			"unreachable code",
+			"fun f(x) {\n  if (x) {\n    return 1;\n  } else {\n    throw 2;\n  }\n  print 3;\n  print 4;\n}\nf(1);",
+			[][2]any{{UnreachableCode, 7}},
+		},
+	}
+
+	for _, c := range cases {
+		found := lint(t, c.source, Config{})
+		if !reflect.DeepEqual(found, c.expected) {
+			t.Fatalf("%s: expecting %v, but got: %v", c.name, c.expected, found)
+		}
+	}
+}
+
+func TestDisableComments(t *testing.T) {
+	source := `var x = 1;
+print x == x; // lint:disable-line self-comparison
+// lint:disable-next-line
+print x == x;
+print x == x; // lint:disable-line unused-variable
+`
+	expected := [][2]any{{SelfComparison, 5}}
+	if found := lint(t, source, Config{}); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("expecting %v, but got: %v", expected, found)
+	}
+}
+
+func TestConfigDisablesRules(t *testing.T) {
+	source := "var x = 1;\nprint x == x;\nif (true) print x;"
+	found := lint(t, source, Config{Disabled: map[string]bool{SelfComparison: true}})
+
+	expected := [][2]any{{ConstantCondition, 3}}
+	if !reflect.DeepEqual(found, expected) {
+		t.Fatalf("expecting %v, but got: %v", expected, found)
+	}
+}
+
+func TestDiagnosticsCarrySeverity(t *testing.T) {
+	diagnostics, _ := Lint([]byte("x = 1;"), Config{})
+	if len(diagnostics) != 1 || diagnostics[0].Severity != Error {
+		t.Fatalf("expecting one error diagnostic, but got: %v", diagnostics)
+	}
+}
@@ -0,0 +1,191 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+)
+
+// The subset of the Language Server Protocol types the server uses. Lines
+// and characters are zero-based, and characters count UTF-16 code units,
+// while token columns count bytes; utf16Column and byteColumn convert
+// between the two.
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    Range         `json:"range"`
+}
+
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           int              `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+const (
+	severityError = 1
+
+	symbolKindFunction = 12
+	symbolKindVariable = 13
+
+	textDocumentSyncFull = 1
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+)
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type didOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type textDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// message is any incoming request or notification. Notifications have no ID.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+}
+
+type errorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   responseError    `json:"error"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// tokenRange is the range covered by a token in a document split into
+// lines.
+func tokenRange(lines []string, token core.Token) Range {
+	line := lineAt(lines, token.Line-1)
+	start := Position{Line: token.Line - 1, Character: utf16Column(line, token.Column)}
+	end := Position{Line: token.Line - 1, Character: utf16Column(line, token.Column+len(token.Lexeme))}
+	return Range{Start: start, End: end}
+}
+
+// lineAt is the zero-based line of a document, or "" past its end.
+func lineAt(lines []string, line int) string {
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[line], "\r")
+}
+
+// utf16Column counts the UTF-16 code units in the first column bytes of
+// line.
+func utf16Column(line string, column int) int {
+	units := 0
+	for offset, r := range line {
+		if offset >= column {
+			return units
+		}
+		units += utf16Length(r)
+	}
+	return units + max(column-len(line), 0)
+}
+
+// byteColumn is the byte offset in line of a character counted in UTF-16
+// code units. A character between the halves of a surrogate pair
+// maps to the end of its rune.
+func byteColumn(line string, character int) int {
+	units := 0
+	for offset, r := range line {
+		if units >= character {
+			return offset
+		}
+		units += utf16Length(r)
+	}
+	return len(line) + max(character-units, 0)
+}
+
+// utf16Length is the number of UTF-16 code units encoding r: two for runes
+// outside the Basic Multilingual Plane, one otherwise.
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
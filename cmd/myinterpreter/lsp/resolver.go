@@ -0,0 +1,320 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// declaration is where a name is bound. Natives have no source position.
+type declaration struct {
+	name   core.Token
+	detail string
+	native bool
+}
+
+// reference is a name in the source and the declaration it resolves to.
+// Declarations are references to themselves.
+type reference struct {
+	token       core.Token
+	declaration *declaration
+}
+
+// resolver binds every name of a script to its declaration by walking the
+// syntax tree with the same nesting of scopes the interpreter's environments
+// have at runtime.
+type resolver struct {
+	scopes     []map[string]*declaration
+	references []reference
+}
+
+// resolve returns the references found in statements, in source order.
+func resolve(statements []core.Statement) []reference {
+	globals := map[string]*declaration{}
+	for _, name := range visitor.NativeNames() {
+		globals[name] = &declaration{name: core.Token{Lexeme: name}, detail: "native fn " + name, native: true}
+	}
+	for _, name := range visitor.NativeConstantNames() {
+		globals[name].detail = "native const " + name
+	}
+
+	r := resolver{scopes: []map[string]*declaration{globals}}
+	// Functions may call globals declared further down, so top-level names
+	// are bound before anything is resolved.
+	for _, statement := range statements {
+		switch node := statement.(type) {
+		case core.VarStmt:
+			globals[node.Name.Lexeme] = &declaration{name: node.Name, detail: "var " + node.Name.Lexeme}
+		case core.FunctionStmt:
+			globals[node.Name.Lexeme] = &declaration{name: node.Name, detail: functionSignature(node)}
+		case core.ImportStmt:
+			for _, name := range node.Bindings() {
+				globals[name.Lexeme] = &declaration{name: name, detail: importDetail(node, name)}
+			}
+		}
+	}
+
+	r.statements(statements)
+	return r.references
+}
+
+func functionSignature(stmt core.FunctionStmt) string {
+	params := []string{}
+	for _, param := range stmt.Params {
+		params = append(params, param.Lexeme)
+	}
+	return fmt.Sprintf("fun %s(%s)", stmt.Name.Lexeme, strings.Join(params, ", "))
+}
+
+// importDetail describes a name bound by an import.
+func importDetail(stmt core.ImportStmt, binding core.Token) string {
+	if stmt.Names == nil {
+		return fmt.Sprintf("module %s", stmt.Path.Lexeme)
+	}
+	for _, name := range stmt.Names {
+		if name.Alias == binding {
+			return fmt.Sprintf("%s from %s", name.Name.Lexeme, stmt.Path.Lexeme)
+		}
+	}
+	return binding.Lexeme
+}
+
+func (r *resolver) beginScope() {
+	r.scopes = append(r.scopes, map[string]*declaration{})
+}
+
+func (r *resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *resolver) declare(name core.Token, detail string) {
+	current := r.scopes[len(r.scopes)-1]
+	binding, ok := current[name.Lexeme]
+	if len(r.scopes) > 1 || !ok || binding.name.Line != name.Line || binding.name.Column != name.Column {
+		binding = &declaration{name: name, detail: detail}
+		current[name.Lexeme] = binding
+	}
+
+	r.references = append(r.references, reference{token: name, declaration: binding})
+}
+
+func (r *resolver) use(name core.Token) {
+	for index := len(r.scopes) - 1; index >= 0; index-- {
+		if binding, ok := r.scopes[index][name.Lexeme]; ok {
+			r.references = append(r.references, reference{token: name, declaration: binding})
+			return
+		}
+	}
+}
+
+func (r *resolver) statements(statements []core.Statement) {
+	for _, statement := range statements {
+		statement.Accept(r)
+	}
+}
+
+func (r *resolver) expression(expr core.Expression) {
+	if expr != nil {
+		expr.Accept(r)
+	}
+}
+
+func (r *resolver) VisitExpressionStmt(stmt core.ExpressionStmt) (any, core.Error) {
+	r.expression(stmt.Expr)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitPrintStmt(stmt core.PrintStmt) (any, core.Error) {
+	r.expression(stmt.Expr)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitVarStmt(stmt core.VarStmt) (any, core.Error) {
+	r.expression(stmt.Initializer)
+	r.declare(stmt.Name, "var "+stmt.Name.Lexeme)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitBlockStmt(stmt core.BlockStmt) (any, core.Error) {
+	r.beginScope()
+	r.statements(stmt.Statements)
+	r.endScope()
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitFunctionStmt(stmt core.FunctionStmt) (any, core.Error) {
+	r.declare(stmt.Name, functionSignature(stmt))
+
+	r.beginScope()
+	for _, param := range stmt.Params {
+		r.declare(param, "parameter "+param.Lexeme)
+	}
+	r.statements(stmt.Body)
+	r.endScope()
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitReturnStmt(stmt core.ReturnStmt) (any, core.Error) {
+	r.expression(stmt.Value)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitThrowStmt(stmt core.ThrowStmt) (any, core.Error) {
+	r.expression(stmt.Value)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitTryStmt(stmt core.TryStmt) (any, core.Error) {
+	r.beginScope()
+	r.statements(stmt.Body)
+	r.endScope()
+
+	if stmt.HasCatch {
+		r.beginScope()
+		r.declare(stmt.CatchName, "catch variable "+stmt.CatchName.Lexeme)
+		r.statements(stmt.CatchBody)
+		r.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		r.beginScope()
+		r.statements(stmt.FinallyBody)
+		r.endScope()
+	}
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitIfStmt(stmt core.IfStmt) (any, core.Error) {
+	r.expression(stmt.Condition)
+	stmt.ThenBranch.Accept(r)
+	if stmt.ElseBranch != nil {
+		stmt.ElseBranch.Accept(r)
+	}
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitWhileStmt(stmt core.WhileStmt) (any, core.Error) {
+	r.expression(stmt.Condition)
+	stmt.Body.Accept(r)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitForStmt(stmt core.ForStmt) (any, core.Error) {
+	r.beginScope()
+	if stmt.Initializer != nil {
+		stmt.Initializer.Accept(r)
+	}
+	r.expression(stmt.Condition)
+	r.expression(stmt.Increment)
+	stmt.Body.Accept(r)
+	r.endScope()
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitImportStmt(stmt core.ImportStmt) (any, core.Error) {
+	for _, name := range stmt.Bindings() {
+		r.declare(name, importDetail(stmt, name))
+	}
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
+	r.expression(expr.Left)
+	r.expression(expr.Right)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitGroupExpr(expr core.Grouping) (any, core.Error) {
+	r.expression(expr.Expr)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitLiteralExpr(expr core.Literal) (any, core.Error) {
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitUnaryExpr(expr core.Unary) (any, core.Error) {
+	r.expression(expr.Right)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitVariableExpr(expr core.Variable) (any, core.Error) {
+	r.use(expr.Name)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitAssignExpr(expr core.Assign) (any, core.Error) {
+	r.expression(expr.Value)
+	r.use(expr.Name)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitCompoundAssignExpr(expr core.CompoundAssign) (any, core.Error) {
+	r.expression(expr.Value)
+	r.use(expr.Name)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitIncrementExpr(expr core.Increment) (any, core.Error) {
+	r.use(expr.Name)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitConditionalExpr(expr core.Conditional) (any, core.Error) {
+	r.expression(expr.Condition)
+	r.expression(expr.Then)
+	r.expression(expr.Else)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitLogicalExpr(expr core.Logical) (any, core.Error) {
+	r.expression(expr.Left)
+	r.expression(expr.Right)
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitCallExpr(expr core.Call) (any, core.Error) {
+	r.expression(expr.Callee)
+	for _, argument := range expr.Arguments {
+		r.expression(argument)
+	}
+	return nil, core.Error{}
+}
+
+func (r *resolver) VisitGetExpr(expr core.Get) (any, core.Error) {
+	r.expression(expr.Object)
+	return nil, core.Error{}
+}
+
+// symbols lists the variables and functions declared by statements, with
+// the declarations inside each function as its children. lines is the
+// document the statements were parsed from.
+func symbols(lines []string, statements []core.Statement) []DocumentSymbol {
+	found := []DocumentSymbol{}
+	for _, statement := range statements {
+		switch node := statement.(type) {
+		case core.VarStmt:
+			found = append(found, DocumentSymbol{
+				Name:           node.Name.Lexeme,
+				Kind:           symbolKindVariable,
+				Range:          tokenRange(lines, node.Name),
+				SelectionRange: tokenRange(lines, node.Name),
+			})
+		case core.FunctionStmt:
+			found = append(found, DocumentSymbol{
+				Name:           node.Name.Lexeme,
+				Detail:         functionSignature(node),
+				Kind:           symbolKindFunction,
+				Range:          tokenRange(lines, node.Name),
+				SelectionRange: tokenRange(lines, node.Name),
+				Children:       symbols(lines, node.Body),
+			})
+		case core.BlockStmt:
+			found = append(found, symbols(lines, node.Statements)...)
+		}
+	}
+
+	return found
+}
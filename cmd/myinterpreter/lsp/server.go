@@ -0,0 +1,263 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/formatter"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+// Server is a language server for Lox. It keeps the text of the open
+// documents and reanalyzes a document on every request about it.
+type Server struct {
+	documents map[string]string
+	writer    io.Writer
+}
+
+func CreateServer() Server {
+	return Server{documents: map[string]string{}}
+}
+
+// Serve handles messages from in until the client sends `exit` or closes
+// the stream, writing responses and notifications to out. A body that isn't
+// JSON gets a parse error response; only framing and I/O errors end it.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.writer = out
+	reader := bufio.NewReader(in)
+
+	for {
+		msg, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		var invalid invalidMessage
+		if errors.As(err, &invalid) {
+			// the id can't be known, so the reply has none, as JSON-RPC asks
+			parseErr := responseError{Code: codeParseError, Message: err.Error()}
+			if err := writeMessage(out, errorResponse{JSONRPC: "2.0", Error: parseErr}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr, err := s.handle(msg)
+		if err != nil {
+			return err
+		}
+		if msg.ID == nil {
+			continue
+		}
+
+		if rpcErr != nil {
+			err = writeMessage(out, errorResponse{JSONRPC: "2.0", ID: msg.ID, Error: *rpcErr})
+		} else {
+			err = writeMessage(out, response{JSONRPC: "2.0", ID: msg.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle runs a request or notification. Errors for the client come back as
+// a responseError, while the returned error reports a failure to write to
+// it.
+func (s *Server) handle(msg message) (any, *responseError, error) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           textDocumentSyncFull,
+				"hoverProvider":              true,
+				"definitionProvider":         true,
+				"documentSymbolProvider":     true,
+				"documentFormattingProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "lox"},
+		}, nil, nil
+
+	case "initialized", "shutdown":
+		return nil, nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil, nil
+		}
+		// With full synchronization the last change holds the whole text.
+		s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+
+	case "textDocument/hover":
+		var params positionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		return s.hover(params), nil, nil
+
+	case "textDocument/definition":
+		var params positionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		return s.definition(params), nil, nil
+
+	case "textDocument/documentSymbol":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		text := s.documents[params.TextDocument.URI]
+		statements, _ := analyze(text)
+		return symbols(strings.Split(text, "\n"), statements), nil, nil
+
+	case "textDocument/formatting":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err), nil
+		}
+		return s.formatting(params.TextDocument.URI), nil, nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("Method not found: %s.", msg.Method)}, nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.writer, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// analyze scans and parses text, returning the statements when it is valid
+// and the errors otherwise.
+func analyze(text string) ([]core.Statement, []core.Error) {
+	tokens, errs := scanner.ScanFile([]byte(text))
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	statements, err := parser.Parse(tokens)
+	if err != nil {
+		return nil, []core.Error{*err}
+	}
+	return statements, nil
+}
+
+// publishDiagnostics reports scanner and parser errors, each covering the
+// line it was found on.
+func (s *Server) publishDiagnostics(uri string) error {
+	text := s.documents[uri]
+	lines := strings.Split(text, "\n")
+
+	diagnostics := []Diagnostic{}
+	_, errs := analyze(text)
+	for _, err := range errs {
+		line := err.Line - 1
+		text := lineAt(lines, line)
+		length := utf16Column(text, len(text))
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Start: Position{Line: line}, End: Position{Line: line, Character: length}},
+			Severity: severityError,
+			Source:   "lox",
+			Message:  err.Err.Error(),
+		})
+	}
+
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// referenceAt finds the name under the cursor.
+func (s *Server) referenceAt(params positionParams) *reference {
+	text := s.documents[params.TextDocument.URI]
+	statements, errs := analyze(text)
+	if len(errs) != 0 {
+		return nil
+	}
+
+	line := params.Position.Line + 1
+	column := byteColumn(lineAt(strings.Split(text, "\n"), params.Position.Line), params.Position.Character)
+	for _, ref := range resolve(statements) {
+		start := ref.token.Column
+		end := start + len(ref.token.Lexeme)
+		if ref.token.Line == line && start <= column && column <= end {
+			return &ref
+		}
+	}
+	return nil
+}
+
+func (s *Server) hover(params positionParams) *Hover {
+	ref := s.referenceAt(params)
+	if ref == nil {
+		return nil
+	}
+
+	value := "```lox\n" + ref.declaration.detail + "\n```"
+	if !ref.declaration.native {
+		value += fmt.Sprintf("\nDeclared on line %d.", ref.declaration.name.Line)
+	}
+	lines := strings.Split(s.documents[params.TextDocument.URI], "\n")
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: value}, Range: tokenRange(lines, ref.token)}
+}
+
+func (s *Server) definition(params positionParams) *Location {
+	ref := s.referenceAt(params)
+	if ref == nil || ref.declaration.native {
+		return nil
+	}
+
+	lines := strings.Split(s.documents[params.TextDocument.URI], "\n")
+	return &Location{URI: params.TextDocument.URI, Range: tokenRange(lines, ref.declaration.name)}
+}
+
+// formatting replaces the whole document with its formatted text, or returns
+// nil when it doesn't parse.
+func (s *Server) formatting(uri string) []TextEdit {
+	text := s.documents[uri]
+	formatted, errs := formatter.Format([]byte(text))
+	if len(errs) != 0 {
+		return nil
+	}
+	if string(formatted) == text {
+		return []TextEdit{}
+	}
+
+	end := Position{Line: strings.Count(text, "\n") + 1}
+	return []TextEdit{{Range: Range{End: end}, NewText: string(formatted)}}
+}
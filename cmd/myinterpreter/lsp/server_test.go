@@ -0,0 +1,292 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+const documentURI = "file:///script.lox"
+
+// session plays a scripted client: it frames the given messages, runs the
+// server over them and returns everything the server wrote, in order.
+func session(t *testing.T, messages ...map[string]any) []map[string]any {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		body, _ := json.Marshal(msg)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var out bytes.Buffer
+	server := CreateServer()
+	if err := server.Serve(&in, &out); err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err)
+	}
+
+	replies := []map[string]any{}
+	reader := bufio.NewReader(&out)
+	for {
+		msg, err := readRaw(reader)
+		if err == io.EOF {
+			return replies
+		}
+		if err != nil {
+			t.Fatalf("was not expecting any errors reading replies, but got: %v", err)
+		}
+		replies = append(replies, msg)
+	}
+}
+
+func readRaw(reader *bufio.Reader) (map[string]any, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	var length int
+	fmt.Sscanf(header, "Content-Length: %d", &length)
+	reader.ReadString('\n')
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+
+	var msg map[string]any
+	err = json.Unmarshal(body, &msg)
+	return msg, err
+}
+
+func open(text string) map[string]any {
+	return map[string]any{
+		"method": "textDocument/didOpen",
+		"params": map[string]any{"textDocument": map[string]any{"uri": documentURI, "languageId": "lox", "version": 1, "text": text}},
+	}
+}
+
+func request(id int, method string, line int, character int) map[string]any {
+	return map[string]any{
+		"id":     id,
+		"method": method,
+		"params": map[string]any{
+			"textDocument": map[string]any{"uri": documentURI},
+			"position":     map[string]any{"line": line, "character": character},
+		},
+	}
+}
+
+func reply(t *testing.T, replies []map[string]any, id int) any {
+	t.Helper()
+
+	for _, msg := range replies {
+		if msg["id"] == float64(id) {
+			return msg["result"]
+		}
+	}
+	t.Fatalf("expecting a reply to request %d, but got: %v", id, replies)
+	return nil
+}
+
+func TestInitializeAdvertisesCapabilities(t *testing.T) {
+	replies := session(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"id": 2, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+
+	capabilities := reply(t, replies, 1).(map[string]any)["capabilities"].(map[string]any)
+	for _, name := range []string{"hoverProvider", "definitionProvider", "documentSymbolProvider", "documentFormattingProvider"} {
+		if capabilities[name] != true {
+			t.Fatalf("expecting %s to be advertised, but got: %v", name, capabilities)
+		}
+	}
+	if len(replies) != 2 {
+		t.Fatalf("expecting only the two replies, but got: %v", replies)
+	}
+}
+
+func TestDiagnosticsArePublishedOnOpenAndChange(t *testing.T) {
+	replies := session(t,
+		open("var a = 1;\nprint a +;\n"),
+		map[string]any{
+			"method": "textDocument/didChange",
+			"params": map[string]any{
+				"textDocument":   map[string]any{"uri": documentURI, "version": 2},
+				"contentChanges": []any{map[string]any{"text": "var a = 1;\n"}},
+			},
+		},
+	)
+
+	if len(replies) != 2 {
+		t.Fatalf("expecting two diagnostics notifications, but got: %v", replies)
+	}
+
+	first := replies[0]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(first) != 1 {
+		t.Fatalf("expecting one diagnostic, but got: %v", first)
+	}
+	diagnostic := first[0].(map[string]any)
+	start := diagnostic["range"].(map[string]any)["start"].(map[string]any)
+	if start["line"] != 1.0 || diagnostic["severity"] != 1.0 || diagnostic["message"] == "" {
+		t.Fatalf("expecting an error on the second line, but got: %v", diagnostic)
+	}
+
+	second := replies[1]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(second) != 0 {
+		t.Fatalf("expecting the diagnostics to be cleared, but got: %v", second)
+	}
+}
+
+const program = `var total = 0;
+fun add(amount) {
+  var doubled = amount * 2;
+  total = total + doubled;
+}
+add(1);
+`
+
+func TestHoverShowsDeclaration(t *testing.T) {
+	replies := session(t, open(program),
+		request(1, "textDocument/hover", 5, 1),
+		request(2, "textDocument/hover", 3, 20),
+		request(3, "textDocument/hover", 0, 13),
+	)
+
+	hover := reply(t, replies, 1).(map[string]any)
+	value := hover["contents"].(map[string]any)["value"].(string)
+	if !strings.Contains(value, "fun add(amount)") || !strings.Contains(value, "line 2") {
+		t.Fatalf("expecting the function signature, but got: %q", value)
+	}
+
+	value = reply(t, replies, 2).(map[string]any)["contents"].(map[string]any)["value"].(string)
+	if !strings.Contains(value, "var doubled") || !strings.Contains(value, "line 3") {
+		t.Fatalf("expecting the local declaration, but got: %q", value)
+	}
+
+	if reply(t, replies, 3) != nil {
+		t.Fatalf("expecting no hover outside names, but got: %v", reply(t, replies, 3))
+	}
+}
+
+func TestDefinitionFollowsScopes(t *testing.T) {
+	source := "var x = 1;\n{\n  var x = 2;\n  print x;\n}\nprint x;\n"
+	replies := session(t, open(source),
+		request(1, "textDocument/definition", 3, 8),
+		request(2, "textDocument/definition", 5, 6),
+	)
+
+	for id, expected := range map[int][2]float64{1: {2, 6}, 2: {0, 4}} {
+		location := reply(t, replies, id).(map[string]any)
+		start := location["range"].(map[string]any)["start"].(map[string]any)
+		if location["uri"] != documentURI || start["line"] != expected[0] || start["character"] != expected[1] {
+			t.Fatalf("expecting request %d to point at %v, but got: %v", id, expected, location)
+		}
+	}
+}
+
+func TestPositionsCountUTF16CodeUnits(t *testing.T) {
+	// é is two bytes and one code unit, 😀 four bytes and two code units,
+	// so the second s starts at byte 24 but character 21
+	source := "var s = \"é😀\"; print s;\n"
+	replies := session(t, open(source),
+		request(1, "textDocument/hover", 0, 21),
+		request(2, "textDocument/definition", 0, 22),
+	)
+
+	hover := reply(t, replies, 1).(map[string]any)
+	start := hover["range"].(map[string]any)["start"].(map[string]any)
+	end := hover["range"].(map[string]any)["end"].(map[string]any)
+	if start["line"] != 0.0 || start["character"] != 21.0 || end["character"] != 22.0 {
+		t.Fatalf("expecting the hover to cover characters 21 to 22, but got: %v", hover["range"])
+	}
+
+	location := reply(t, replies, 2).(map[string]any)
+	start = location["range"].(map[string]any)["start"].(map[string]any)
+	if start["line"] != 0.0 || start["character"] != 4.0 {
+		t.Fatalf("expecting the definition at character 4, but got: %v", location)
+	}
+}
+
+func TestColumnConversions(t *testing.T) {
+	line := "\"é😀\" x"
+	cases := []struct{ bytes, units int }{{0, 0}, {1, 1}, {3, 2}, {7, 4}, {8, 5}, {9, 6}, {10, 7}, {12, 9}}
+	for _, c := range cases {
+		if units := utf16Column(line, c.bytes); units != c.units {
+			t.Fatalf("expecting byte %d to be character %d, but got: %d", c.bytes, c.units, units)
+		}
+		if bytes := byteColumn(line, c.units); bytes != c.bytes {
+			t.Fatalf("expecting character %d to be byte %d, but got: %d", c.units, c.bytes, bytes)
+		}
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	replies := session(t, open(program), map[string]any{
+		"id":     1,
+		"method": "textDocument/documentSymbol",
+		"params": map[string]any{"textDocument": map[string]any{"uri": documentURI}},
+	})
+
+	found := reply(t, replies, 1).([]any)
+	if len(found) != 2 {
+		t.Fatalf("expecting two top-level symbols, but got: %v", found)
+	}
+
+	function := found[1].(map[string]any)
+	children := function["children"].([]any)
+	if function["name"] != "add" || function["kind"] != 12.0 || len(children) != 1 || children[0].(map[string]any)["name"] != "doubled" {
+		t.Fatalf("expecting the function with its local, but got: %v", function)
+	}
+}
+
+func TestFormattingReplacesDocument(t *testing.T) {
+	replies := session(t, open("var a=1;\nprint a;"), map[string]any{
+		"id":     1,
+		"method": "textDocument/formatting",
+		"params": map[string]any{"textDocument": map[string]any{"uri": documentURI}, "options": map[string]any{"tabSize": 2}},
+	})
+
+	edits := reply(t, replies, 1).([]any)
+	if len(edits) != 1 || edits[0].(map[string]any)["newText"] != "var a = 1;\nprint a;\n" {
+		t.Fatalf("expecting a single edit with the formatted text, but got: %v", edits)
+	}
+}
+
+func TestUnknownRequestsFail(t *testing.T) {
+	replies := session(t, map[string]any{"id": 1, "method": "textDocument/rename", "params": map[string]any{}})
+
+	if len(replies) != 1 || replies[0]["error"].(map[string]any)["code"] != float64(codeMethodNotFound) {
+		t.Fatalf("expecting a method not found error, but got: %v", replies)
+	}
+}
+
+func TestInvalidJSONGetsParseErrorAndServingGoesOn(t *testing.T) {
+	var in bytes.Buffer
+	for _, body := range []string{`{"jsonrpc": "2.0", "id": 1, "method":`, `{"jsonrpc": "2.0", "id": 2, "method": "initialize", "params": {}}`} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var out bytes.Buffer
+	server := CreateServer()
+	if err := server.Serve(&in, &out); err != nil {
+		t.Fatalf("expecting the server to carry on after invalid JSON, but got: %v", err)
+	}
+
+	reader := bufio.NewReader(&out)
+	parseErr, err := readRaw(reader)
+	if err != nil || parseErr["id"] != nil || parseErr["error"].(map[string]any)["code"] != float64(codeParseError) {
+		t.Fatalf("expecting a parse error without an id, but got: %v (%v)", parseErr, err)
+	}
+	initialized, err := readRaw(reader)
+	if err != nil || initialized["id"] != 2.0 || initialized["result"] == nil {
+		t.Fatalf("expecting the next request to be answered, but got: %v (%v)", initialized, err)
+	}
+}
@@ -0,0 +1,38 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/framing"
+)
+
+// invalidMessage is the error for a well framed body that isn't a JSON-RPC
+// message. The next message can still be read after it.
+type invalidMessage struct {
+	err error
+}
+
+func (e invalidMessage) Error() string {
+	return fmt.Sprintf("Invalid JSON-RPC message: %v.", e.err)
+}
+
+// readMessage reads one JSON-RPC message framed by a Content-Length header.
+func readMessage(reader *bufio.Reader) (message, error) {
+	body, err := framing.Read(reader)
+	if err != nil {
+		return message{}, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return message{}, invalidMessage{err: err}
+	}
+	return msg, nil
+}
+
+func writeMessage(writer io.Writer, value any) error {
+	return framing.Write(writer, value)
+}
@@ -2,15 +2,40 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/dap"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/lsp"
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
 )
 
 func main() {
+	// the language server talks over stdin and stdout, so it takes no file;
+	// editors may still pass flags such as --stdio, which are ignored
+	if len(os.Args) >= 2 && os.Args[1] == "lsp" {
+		server := lsp.CreateServer()
+		if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// the debug adapter is spoken to over stdin and stdout as well, and the
+	// script to debug comes with the client's launch request
+	if len(os.Args) >= 2 && os.Args[1] == "debug" {
+		adapter := dap.CreateAdapter()
+		if err := adapter.Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(os.Args) < 3 {
 		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh tokenize <filename>")
 		os.Exit(1)
@@ -45,30 +70,22 @@ func main() {
 			os.Exit(65)
 		}
 
-		// visit expressions
-		evaluator := visitor.Evaluator{}
-		for _, expr := range expressions {
-			value, err := evaluator.Evaluate(expr)
-			printErrorAndExit(&err)
-
-			if value == nil {
-				fmt.Println("nil")
-			} else {
-				fmt.Println(value)
-			}
-		}
+		printErrorAndExit(evaluate(os.Stdout, expressions))
 
-	case "run":
-		tokens := tokenize(filename, false)
-		statements, err := parser.Parse(tokens)
-		printErrorAndExit(err)
+	case "fmt":
+		formatCommand(os.Args[2:])
 
-		// visit expressions
-		interpreter := visitor.CreateInterpreter()
-		for _, expr := range statements {
-			_, err := interpreter.Interpret(expr)
-			printErrorAndExit(&err)
-		}
+	case "coverage":
+		coverageCommand(os.Args[2:])
+
+	case "test":
+		testCommand(os.Args[2:])
+
+	case "lint":
+		lintCommand(os.Args[2:])
+
+	case "run":
+		runCommand(os.Args[2:])
 
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
@@ -76,14 +93,31 @@ func main() {
 	}
 }
 
-func tokenize(filename string, shouldPrintTokens bool) []core.Token {
-	fileContents, err := os.ReadFile(filename)
+// stdinName is the filename standing for standard input.
+const stdinName = "-"
+
+// readSource reads a script, from standard input when filename is `-`.
+func readSource(filename string) []byte {
+	var source []byte
+	var err error
+	if filename == stdinName {
+		source, err = io.ReadAll(os.Stdin)
+	} else {
+		source, err = os.ReadFile(filename)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
 		os.Exit(1)
 	}
+	return source
+}
 
-	tokens, errors := scanner.ScanFile(fileContents)
+func tokenize(filename string, shouldPrintTokens bool) []core.Token {
+	return scan(readSource(filename), shouldPrintTokens)
+}
+
+func scan(source []byte, shouldPrintTokens bool) []core.Token {
+	tokens, errors := scanner.ScanFile(source)
 	if shouldPrintTokens {
 		printTokens(tokens)
 	}
@@ -111,6 +145,21 @@ func printErrorsAndExit(errors []core.Error) {
 	os.Exit(errors[0].ExitCode)
 }
 
+// evaluate writes the value of each expression the way print shows it, and
+// stops at the first error.
+func evaluate(out io.Writer, expressions []core.Expression) *core.Error {
+	evaluator := visitor.CreateEvaluator()
+	for _, expr := range expressions {
+		value, err := evaluator.Evaluate(expr)
+		if err.Err != nil {
+			return &err
+		}
+
+		fmt.Fprintln(out, visitor.Stringify(value))
+	}
+	return nil
+}
+
 func printErrorAndExit(error *core.Error) {
 	if error == nil {
 		return
@@ -120,5 +169,8 @@ func printErrorAndExit(error *core.Error) {
 	}
 
 	fmt.Fprintf(os.Stderr, "[line %d] Error: %v\n", error.Line, error.Err)
+	if len(error.Trace) > 0 {
+		fmt.Fprintln(os.Stderr, error.StackTrace())
+	}
 	os.Exit(error.ExitCode)
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+func TestEvaluateShowsValuesAsPrintDoes(t *testing.T) {
+	cases := map[string]string{
+		"INF":      "INF",
+		"-INF":     "-INF",
+		"NAN":      "NAN",
+		"-INF * 0": "NAN",
+		"sqrt(2)":  "1.4142135623730951",
+		"1.10d":    "1.10",
+		"3.0":      "3",
+		"nil":      "nil",
+	}
+
+	for source, expected := range cases {
+		tokens, _ := scanner.ScanFile([]byte(source))
+		expressions, parseErr := parser.ParseExpressions(tokens)
+		if parseErr != nil {
+			t.Fatalf("%s: was not expecting any parse errors, but got: %v", source, parseErr.Err)
+		}
+
+		var out strings.Builder
+		if err := evaluate(&out, expressions); err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", source, err.Err)
+		}
+		if out.String() != expected+"\n" {
+			t.Fatalf("%s: expecting %q, but got: %q", source, expected+"\n", out.String())
+		}
+	}
+}
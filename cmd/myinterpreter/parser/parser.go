@@ -11,6 +11,10 @@ var statements = []core.Statement{}
 var expressions = []core.Expression{}
 var position = 0
 
+// functionDepth counts the function bodies being parsed, so `return` can be
+// rejected at the top level.
+var functionDepth = 0
+
 func current() core.Token {
 	return tokens[position]
 }
@@ -55,6 +59,24 @@ func statement() (core.Statement, *core.Error) {
 	if match(core.PRINT) {
 		return printStatement()
 	}
+	if match(core.RETURN) {
+		return returnStatement()
+	}
+	if match(core.THROW) {
+		return throwStatement()
+	}
+	if match(core.TRY) {
+		return tryStatement()
+	}
+	if match(core.IF) {
+		return ifStatement()
+	}
+	if match(core.WHILE) {
+		return whileStatement()
+	}
+	if match(core.FOR) {
+		return forStatement()
+	}
 	if match(core.LEFT_BRACE) {
 		return blockStatement()
 	}
@@ -65,9 +87,148 @@ func declaration() (core.Statement, *core.Error) {
 	if match(core.VAR) {
 		return varDeclaration()
 	}
+	if match(core.FUN) {
+		return functionDeclaration()
+	}
+	if match(core.IMPORT) {
+		return importDeclaration()
+	}
 	return statement()
 }
 
+// importDeclaration parses `import "path" as alias;` and
+// `import { name, name as alias } from "path";`. `as` and `from` are only
+// keywords here, so scripts may still use them as names.
+func importDeclaration() (core.Statement, *core.Error) {
+	stmt := core.ImportStmt{Keyword: previous()}
+
+	if match(core.LEFT_BRACE) {
+		for {
+			if !match(core.IDENTIFIER) {
+				return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect name to import."), ExitCode: 65}
+			}
+			name := core.ImportName{Name: previous(), Alias: previous()}
+			if matchContextual("as") {
+				if !match(core.IDENTIFIER) {
+					return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect name after 'as'."), ExitCode: 65}
+				}
+				name.Alias = previous()
+			}
+			stmt.Names = append(stmt.Names, name)
+
+			if !match(core.COMMA) {
+				break
+			}
+		}
+
+		if !match(core.RIGHT_BRACE) {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect '}' after imported names."), ExitCode: 65}
+		}
+		if !matchContextual("from") {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect 'from' after imported names."), ExitCode: 65}
+		}
+	}
+
+	if !match(core.STRING) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect module path."), ExitCode: 65}
+	}
+	stmt.Path = previous()
+
+	if stmt.Names == nil {
+		if !matchContextual("as") {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect 'as' after module path."), ExitCode: 65}
+		}
+		if !match(core.IDENTIFIER) {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect name after 'as'."), ExitCode: 65}
+		}
+		stmt.Alias = previous()
+	}
+
+	if !match(core.SEMICOLON) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ';' after import."), ExitCode: 65}
+	}
+	return stmt, nil
+}
+
+// matchContextual matches an identifier acting as a keyword in one place
+// of the grammar only.
+func matchContextual(word string) bool {
+	if current().Type == core.IDENTIFIER && current().Lexeme == word {
+		advance()
+		return true
+	}
+	return false
+}
+
+func functionDeclaration() (core.Statement, *core.Error) {
+	if !match(core.IDENTIFIER) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect function name."), ExitCode: 65}
+	}
+	name := previous()
+
+	if !match(core.LEFT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect '(' after function name."), ExitCode: 65}
+	}
+
+	params := []core.Token{}
+	if current().Type != core.RIGHT_PAREN {
+		for {
+			if len(params) >= 255 {
+				return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Can't have more than 255 parameters."), ExitCode: 65}
+			}
+
+			if !match(core.IDENTIFIER) {
+				return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect parameter name."), ExitCode: 65}
+			}
+			params = append(params, previous())
+
+			if !match(core.COMMA) {
+				break
+			}
+		}
+	}
+
+	if !match(core.RIGHT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ')' after parameters."), ExitCode: 65}
+	}
+
+	if !match(core.LEFT_BRACE) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect '{' before function body."), ExitCode: 65}
+	}
+
+	functionDepth++
+	body, err := blockStatement()
+	functionDepth--
+	if err != nil {
+		return nil, err
+	}
+
+	return core.FunctionStmt{Name: name, Params: params, Body: body.(core.BlockStmt).Statements}, nil
+}
+
+func returnStatement() (core.Statement, *core.Error) {
+	keyword := previous()
+	if functionDepth == 0 {
+		return nil, &core.Error{Line: keyword.Line, Err: fmt.Errorf("Can't return from top-level code."), ExitCode: 65}
+	}
+
+	var value core.Expression
+	if current().Type != core.SEMICOLON {
+		var err *core.Error
+		value, err = expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := isNextTokenSemicolon()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.ReturnStmt{Keyword: keyword, Value: value}, nil
+}
+
 func varDeclaration() (core.Statement, *core.Error) {
 	var err *core.Error
 
@@ -95,6 +256,7 @@ func varDeclaration() (core.Statement, *core.Error) {
 }
 
 func printStatement() (core.Statement, *core.Error) {
+	keyword := previous()
 	value, err := expression()
 	if err != nil {
 		return nil, err
@@ -105,7 +267,7 @@ func printStatement() (core.Statement, *core.Error) {
 		return nil, err
 	}
 
-	return core.PrintStmt{Expr: value}, nil
+	return core.PrintStmt{Keyword: keyword, Expr: value}, nil
 }
 
 func blockStatement() (core.Statement, *core.Error) {
@@ -128,6 +290,182 @@ func blockStatement() (core.Statement, *core.Error) {
 	return core.BlockStmt{Statements: blockStatements}, nil
 }
 
+func ifStatement() (core.Statement, *core.Error) {
+	keyword := previous()
+	condition, err := parenthesizedCondition("if")
+	if err != nil {
+		return nil, err
+	}
+
+	thenBranch, err := statement()
+	if err != nil {
+		return nil, err
+	}
+
+	var elseBranch core.Statement
+	if match(core.ELSE) {
+		elseBranch, err = statement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return core.IfStmt{Keyword: keyword, Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}, nil
+}
+
+func whileStatement() (core.Statement, *core.Error) {
+	keyword := previous()
+	condition, err := parenthesizedCondition("while")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := statement()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.WhileStmt{Keyword: keyword, Condition: condition, Body: body}, nil
+}
+
+func forStatement() (core.Statement, *core.Error) {
+	keyword := previous()
+	if !match(core.LEFT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect '(' after 'for'."), ExitCode: 65}
+	}
+
+	var initializer core.Statement
+	var err *core.Error
+	if match(core.SEMICOLON) {
+		initializer = nil
+	} else if match(core.VAR) {
+		initializer, err = varDeclaration()
+	} else {
+		initializer, err = expressionStatement()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var condition core.Expression
+	if current().Type != core.SEMICOLON {
+		condition, err = expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !match(core.SEMICOLON) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ';' after loop condition."), ExitCode: 65}
+	}
+
+	var increment core.Expression
+	if current().Type != core.RIGHT_PAREN {
+		increment, err = expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !match(core.RIGHT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ')' after for clauses."), ExitCode: 65}
+	}
+
+	body, err := statement()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.ForStmt{Keyword: keyword, Initializer: initializer, Condition: condition, Increment: increment, Body: body}, nil
+}
+
+func parenthesizedCondition(keyword string) (core.Expression, *core.Error) {
+	if !match(core.LEFT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect '(' after '%s'.", keyword), ExitCode: 65}
+	}
+
+	condition, err := expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !match(core.RIGHT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ')' after %s condition.", keyword), ExitCode: 65}
+	}
+
+	return condition, nil
+}
+
+func throwStatement() (core.Statement, *core.Error) {
+	keyword := previous()
+	value, err := expression()
+	if err != nil {
+		return nil, err
+	}
+
+	err = isNextTokenSemicolon()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.ThrowStmt{Keyword: keyword, Value: value}, nil
+}
+
+func tryStatement() (core.Statement, *core.Error) {
+	keyword := previous()
+	body, err := block("Expect '{' after 'try'.")
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := core.TryStmt{Keyword: keyword, Body: body}
+
+	if match(core.CATCH) {
+		if !match(core.LEFT_PAREN) {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect '(' after 'catch'."), ExitCode: 65}
+		}
+		if !match(core.IDENTIFIER) {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect error variable name."), ExitCode: 65}
+		}
+		stmt.HasCatch = true
+		stmt.CatchName = previous()
+		if !match(core.RIGHT_PAREN) {
+			return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ')' after error variable name."), ExitCode: 65}
+		}
+
+		stmt.CatchBody, err = block("Expect '{' after catch clause.")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if match(core.FINALLY) {
+		stmt.FinallyBody, err = block("Expect '{' after 'finally'.")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !stmt.HasCatch && stmt.FinallyBody == nil {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect 'catch' or 'finally' after try block."), ExitCode: 65}
+	}
+
+	return stmt, nil
+}
+
+// block parses a braced block that must start at the current token and
+// returns its statements.
+func block(message string) ([]core.Statement, *core.Error) {
+	if !match(core.LEFT_BRACE) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf(message), ExitCode: 65}
+	}
+
+	stmt, err := blockStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.(core.BlockStmt).Statements, nil
+}
+
 func expressionStatement() (core.Statement, *core.Error) {
 	expr, err := expression()
 	if err != nil {
@@ -147,7 +485,7 @@ func expression() (core.Expression, *core.Error) {
 }
 
 func assignment() (core.Expression, *core.Error) {
-	expr, err := equality()
+	expr, err := conditional()
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +503,110 @@ func assignment() (core.Expression, *core.Error) {
 		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Invalid assignment target."), ExitCode: 65}
 	}
 
+	if match(core.PLUS_EQUAL, core.MINUS_EQUAL, core.STAR_EQUAL, core.SLASH_EQUAL) {
+		operator := previous()
+		value, err := assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		if variableExpr, ok := expr.(core.Variable); ok {
+			return core.CompoundAssign{Name: variableExpr.Name, Operator: operator, Value: value}, nil
+		}
+
+		return nil, &core.Error{Line: operator.Line, Err: fmt.Errorf("Invalid assignment target."), ExitCode: 65}
+	}
+
+	return expr, nil
+}
+
+// conditional parses `cond ? then : else`, which is right-associative so
+// `a ? b : c ? d : e` groups as `a ? b : (c ? d : e)`.
+func conditional() (core.Expression, *core.Error) {
+	expr, err := coalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	if match(core.QUESTION) {
+		question := previous()
+		thenBranch, err := expression()
+		if err != nil {
+			return nil, err
+		}
+
+		if !match(core.COLON) {
+			err := fmt.Errorf("Expect ':' after then branch of conditional expression.")
+			return nil, &core.Error{Line: current().Line, Err: err, ExitCode: 65}
+		}
+
+		elseBranch, err := conditional()
+		if err != nil {
+			return nil, err
+		}
+
+		return core.Conditional{Question: question, Condition: expr, Then: thenBranch, Else: elseBranch}, nil
+	}
+
+	return expr, nil
+}
+
+// coalesce parses `a ?? b`, which groups to the left like the other binary
+// operators and binds more loosely than `or`.
+func coalesce() (core.Expression, *core.Error) {
+	expr, err := or()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.QUESTION_QUESTION) {
+		operator := previous()
+		right, err := or()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Logical{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func or() (core.Expression, *core.Error) {
+	expr, err := and()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.OR) {
+		operator := previous()
+		right, err := and()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Logical{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func and() (core.Expression, *core.Error) {
+	expr, err := equality()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.AND) {
+		operator := previous()
+		right, err := equality()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Logical{Left: expr, Operator: operator, Right: right}
+	}
+
 	return expr, nil
 }
 
@@ -176,7 +618,7 @@ func equality() (core.Expression, *core.Error) {
 
 	for match(core.EQUAL_EQUAL, core.BANG_EQUAL) {
 		operator := previous()
-		right, err := equality()
+		right, err := comparison()
 		if err != nil {
 			return nil, err
 		}
@@ -188,12 +630,88 @@ func equality() (core.Expression, *core.Error) {
 }
 
 func comparison() (core.Expression, *core.Error) {
-	expr, err := term()
+	expr, err := bitwiseOr()
 	if err != nil {
 		return nil, err
 	}
 
 	for match(core.GREATER, core.GREATER_EQUAL, core.LESS, core.LESS_EQUAL) {
+		operator := previous()
+		right, err := bitwiseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func bitwiseOr() (core.Expression, *core.Error) {
+	expr, err := bitwiseXor()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.PIPE) {
+		operator := previous()
+		right, err := bitwiseXor()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func bitwiseXor() (core.Expression, *core.Error) {
+	expr, err := bitwiseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.CARET) {
+		operator := previous()
+		right, err := bitwiseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func bitwiseAnd() (core.Expression, *core.Error) {
+	expr, err := shift()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.AMPERSAND) {
+		operator := previous()
+		right, err := shift()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = core.Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func shift() (core.Expression, *core.Error) {
+	expr, err := term()
+	if err != nil {
+		return nil, err
+	}
+
+	for match(core.LESS_LESS, core.GREATER_GREATER) {
 		operator := previous()
 		right, err := term()
 		if err != nil {
@@ -231,7 +749,7 @@ func factor() (core.Expression, *core.Error) {
 		return expr, err
 	}
 
-	for match(core.SLASH, core.STAR) {
+	for match(core.SLASH, core.STAR, core.PERCENT, core.TILDE_SLASH) {
 		operator := previous()
 		right, err := unary()
 		if err != nil {
@@ -244,8 +762,29 @@ func factor() (core.Expression, *core.Error) {
 	return expr, nil
 }
 
+// unary parses prefix operators. Since `--` is the decrement operator,
+// negating twice needs a space or parentheses, `- -x` or `-(-x)`: `--5` is
+// an invalid decrement rather than 5.
 func unary() (core.Expression, *core.Error) {
-	if match(core.BANG, core.MINUS) {
+	if match(core.PLUS_PLUS, core.MINUS_MINUS) {
+		operator := previous()
+		operand, err := unary()
+		if err != nil {
+			return nil, err
+		}
+
+		if variableExpr, ok := operand.(core.Variable); ok {
+			return core.Increment{Name: variableExpr.Name, Operator: operator, Prefix: true}, nil
+		}
+
+		if operator.Type == core.MINUS_MINUS {
+			err := fmt.Errorf("Invalid increment target. Write '- -' to negate twice.")
+			return nil, &core.Error{Line: operator.Line, Err: err, ExitCode: 65}
+		}
+		return nil, &core.Error{Line: operator.Line, Err: fmt.Errorf("Invalid increment target."), ExitCode: 65}
+	}
+
+	if match(core.BANG, core.MINUS, core.TILDE) {
 		operator := previous()
 		right, err := unary()
 		if err != nil {
@@ -254,7 +793,98 @@ func unary() (core.Expression, *core.Error) {
 		return core.Unary{Operator: operator, Right: right}, nil
 	}
 
-	return primary()
+	return exponent()
+}
+
+// exponent binds tighter than unary operators on its left (-2 ** 2 is -4) and
+// is right-associative, so its right operand goes back through unary().
+func exponent() (core.Expression, *core.Error) {
+	expr, err := postfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if match(core.STAR_STAR) {
+		operator := previous()
+		right, err := unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return core.Binary{Left: expr, Operator: operator, Right: right}, nil
+	}
+
+	return expr, nil
+}
+
+func postfix() (core.Expression, *core.Error) {
+	expr, err := call()
+	if err != nil {
+		return nil, err
+	}
+
+	if match(core.PLUS_PLUS, core.MINUS_MINUS) {
+		operator := previous()
+		if variableExpr, ok := expr.(core.Variable); ok {
+			return core.Increment{Name: variableExpr.Name, Operator: operator, Prefix: false}, nil
+		}
+
+		return nil, &core.Error{Line: operator.Line, Err: fmt.Errorf("Invalid increment target."), ExitCode: 65}
+	}
+
+	return expr, nil
+}
+
+func call() (core.Expression, *core.Error) {
+	expr, err := primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if match(core.LEFT_PAREN) {
+			expr, err = finishCall(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if match(core.DOT) {
+			if !match(core.IDENTIFIER) {
+				return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect property name after '.'."), ExitCode: 65}
+			}
+			expr = core.Get{Object: expr, Name: previous()}
+		} else {
+			break
+		}
+	}
+
+	return expr, nil
+}
+
+func finishCall(callee core.Expression) (core.Expression, *core.Error) {
+	arguments := []core.Expression{}
+	if current().Type != core.RIGHT_PAREN {
+		for {
+			if len(arguments) >= 255 {
+				return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Can't have more than 255 arguments."), ExitCode: 65}
+			}
+
+			argument, err := expression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+
+			if !match(core.COMMA) {
+				break
+			}
+		}
+	}
+
+	if !match(core.RIGHT_PAREN) {
+		return nil, &core.Error{Line: current().Line, Err: fmt.Errorf("Expect ')' after arguments."), ExitCode: 65}
+	}
+
+	return core.Call{Callee: callee, Paren: previous(), Arguments: arguments}, nil
 }
 
 func primary() (core.Expression, *core.Error) {
@@ -270,7 +900,7 @@ func primary() (core.Expression, *core.Error) {
 		return core.Literal{Value: nil}, nil
 	}
 
-	if match(core.NUMBER, core.STRING) {
+	if match(core.NUMBER, core.DECIMAL, core.STRING) {
 		return core.Literal{Value: previous().Literal}, nil
 	}
 
@@ -302,6 +932,9 @@ func primary() (core.Expression, *core.Error) {
 
 func Parse(scannedTokens []core.Token) ([]core.Statement, *core.Error) {
 	tokens = scannedTokens
+	position = 0
+	functionDepth = 0
+	statements = []core.Statement{}
 	for !isAtEnd() {
 		stmt, err := declaration()
 		if err != nil {
@@ -315,6 +948,8 @@ func Parse(scannedTokens []core.Token) ([]core.Statement, *core.Error) {
 
 func ParseExpressions(scannedTokens []core.Token) ([]core.Expression, *core.Error) {
 	tokens = scannedTokens
+	position = 0
+	expressions = []core.Expression{}
 	for !isAtEnd() {
 		expr, err := expression()
 		if err != nil {
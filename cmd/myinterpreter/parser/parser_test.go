@@ -1,5 +1,94 @@
 package parser
 
+import (
+	"fmt"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+// parseOne parses source holding a single expression.
+func parseOne(t *testing.T, source string) (core.Expression, *core.Error) {
+	t.Helper()
+
+	scanned, errs := scanner.ScanFile([]byte(source))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors for %q, but got: %v", source, errs[0].Err)
+	}
+	parsed, err := ParseExpressions(scanned)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("there should be 1 expression in %q, but got: %d", source, len(parsed))
+	}
+	return parsed[0], nil
+}
+
+// grouping writes an expression with every operation in parentheses, which
+// is enough to see how operators grouped.
+func grouping(expr core.Expression) string {
+	switch node := expr.(type) {
+	case core.Binary:
+		return fmt.Sprintf("(%s %s %s)", grouping(node.Left), node.Operator.Lexeme, grouping(node.Right))
+	case core.Unary:
+		return fmt.Sprintf("(%s%s)", node.Operator.Lexeme, grouping(node.Right))
+	case core.Logical:
+		return fmt.Sprintf("(%s %s %s)", grouping(node.Left), node.Operator.Lexeme, grouping(node.Right))
+	case core.Conditional:
+		return fmt.Sprintf("(%s ? %s : %s)", grouping(node.Condition), grouping(node.Then), grouping(node.Else))
+	case core.Assign:
+		return fmt.Sprintf("(%s = %s)", node.Name.Lexeme, grouping(node.Value))
+	case core.Grouping:
+		return grouping(node.Expr)
+	case core.Literal:
+		return fmt.Sprint(node.Value)
+	case core.Variable:
+		return node.Name.Lexeme
+	}
+	return fmt.Sprintf("<%T>", expr)
+}
+
+func TestArithmeticAndBitwiseOperatorPrecedence(t *testing.T) {
+	cases := map[string]string{
+		"7 % 3 * 2":        "((7 % 3) * 2)",
+		"1 + 7 ~/ 2":       "(1 + (7 ~/ 2))",
+		"8 ~/ 2 ~/ 2":      "((8 ~/ 2) ~/ 2)",
+		"2 ** 3 ** 2":      "(2 ** (3 ** 2))",
+		"-2 ** 2":          "(-(2 ** 2))",
+		"2 ** -1":          "(2 ** (-1))",
+		"2 * 3 ** 2":       "(2 * (3 ** 2))",
+		"~1 ** 2":          "(~(1 ** 2))",
+		"1 << 2 + 3":       "(1 << (2 + 3))",
+		"1 | 2 ^ 3 & 4":    "(1 | (2 ^ (3 & 4)))",
+		"a & b == c":       "((a & b) == c)",
+		"1 | 2 < 3":        "((1 | 2) < 3)",
+		"x >> 1 << 2":      "((x >> 1) << 2)",
+		"~a & ~b":          "((~a) & (~b))",
+		"(1 + 2) ~/ 2 % 2": "(((1 + 2) ~/ 2) % 2)",
+		"f() ~/ 2":         "(<core.Call> ~/ 2)",
+	}
+
+	for source, expected := range cases {
+		expr, err := parseOne(t, source)
+		if err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if got := grouping(expr); got != expected {
+			t.Fatalf("expecting %q to parse as %s, but got: %s", source, expected, got)
+		}
+	}
+}
+
+func TestOperatorsNeedOperands(t *testing.T) {
+	for _, source := range []string{"1 %", "2 ** ", "1 & ", "~", "1 << >> 2", "1 ^ * 2"} {
+		if _, err := parseOne(t, source); err == nil {
+			t.Fatalf("was expecting a parse error for %q, but didn't get one", source)
+		}
+	}
+}
+
 // // isAtEnd() should return FALSE if NOT reached EOF token
 // func TestIsAtEndReturnsFalseIfNotEOFToken(t *testing.T) {
 // 	tokens := []core.Token{
@@ -234,3 +323,74 @@ package parser
 // 		t.Fatalf("was expecting an Group expression, but got: %v", e[0])
 // 	}
 // }
+
+func TestIncrementAndCompoundAssignmentTargets(t *testing.T) {
+	valid := map[string]string{
+		"x += 1":   "core.CompoundAssign",
+		"x /= y":   "core.CompoundAssign",
+		"x++":      "core.Increment",
+		"--x":      "core.Increment",
+		"- -x":     "core.Unary",
+		"-(-x)":    "core.Unary",
+		"a = b++":  "core.Assign",
+		"x -= y--": "core.CompoundAssign",
+	}
+	for source, expected := range valid {
+		expr, err := parseOne(t, source)
+		if err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if got := fmt.Sprintf("%T", expr); got != expected {
+			t.Fatalf("expecting %q to parse as %s, but got: %s", source, expected, got)
+		}
+	}
+
+	invalid := map[string]string{
+		"1++":      "Invalid increment target.",
+		"(x)--":    "Invalid increment target.",
+		"++1":      "Invalid increment target.",
+		"--5":      "Invalid increment target. Write '- -' to negate twice.",
+		"--(-x)":   "Invalid increment target. Write '- -' to negate twice.",
+		"(x) += 1": "Invalid assignment target.",
+		"1 -= 2":   "Invalid assignment target.",
+		"a.b *= 2": "Invalid assignment target.",
+	}
+	for source, expected := range invalid {
+		_, err := parseOne(t, source)
+		if err == nil || err.Err.Error() != expected || err.ExitCode != 65 {
+			t.Fatalf("expecting %q to fail with %q, but got: %v", source, expected, err)
+		}
+	}
+}
+
+func TestConditionalAndCoalescePrecedence(t *testing.T) {
+	cases := map[string]string{
+		"a ?? b ?? c":         "((a ?? b) ?? c)",
+		"a ? b : c ? d : e":   "(a ? b : (c ? d : e))",
+		"a ? b ? c : d : e":   "(a ? (b ? c : d) : e)",
+		"a ?? b ? c : d":      "((a ?? b) ? c : d)",
+		"a ? b ?? c : d ?? e": "(a ? (b ?? c) : (d ?? e))",
+		"a ?? b or c":         "(a ?? (b or c))",
+		"a or b ?? c and d":   "((a or b) ?? (c and d))",
+		"a ?? b == c":         "(a ?? (b == c))",
+		"x = a ? b : c":       "(x = (a ? b : c))",
+		"x = a ?? b":          "(x = (a ?? b))",
+		"a ? x = 1 : (x = 2)": "(a ? (x = 1) : (x = 2))",
+	}
+
+	for source, expected := range cases {
+		expr, err := parseOne(t, source)
+		if err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if got := grouping(expr); got != expected {
+			t.Fatalf("expecting %q to parse as %s, but got: %s", source, expected, got)
+		}
+	}
+
+	for _, source := range []string{"a ? b", "a ? : c", "a ??", "?? b"} {
+		if _, err := parseOne(t, source); err == nil {
+			t.Fatalf("was expecting a parse error for %q, but didn't get one", source)
+		}
+	}
+}
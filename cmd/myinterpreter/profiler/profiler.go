@@ -0,0 +1,239 @@
+// Package profiler measures where a script spends its time, per source line
+// and per function.
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// Profiler is an interpreter hook timing statements: the time until the next
+// statement starts, or until the call it is in returns, is charged to the
+// one running, together with the stack of calls that led to it.
+type Profiler struct {
+	now func() time.Time
+
+	started time.Time
+	current []visitor.Frame
+
+	stacks    map[string]*stack
+	lines     map[location]time.Duration
+	functions map[function]*functionTime
+}
+
+// location is a line of one of the files making up the program.
+type location struct {
+	script string
+	line   int
+}
+
+// function is a function of one of the files, two files being free to
+// declare functions of the same name.
+type function struct {
+	script string
+	name   string
+}
+
+// stack is a stack of calls, outermost first, with the line the innermost
+// function was running.
+type stack struct {
+	functions []function
+	line      int
+	elapsed   time.Duration
+}
+
+type functionTime struct {
+	self  time.Duration
+	total time.Duration
+}
+
+func CreateProfiler() *Profiler {
+	return &Profiler{
+		now:       time.Now,
+		stacks:    map[string]*stack{},
+		lines:     map[location]time.Duration{},
+		functions: map[function]*functionTime{},
+	}
+}
+
+func (p *Profiler) BeforeStatement(interpreter *visitor.Interpreter, stmt core.Statement, line int) error {
+	if line == 0 {
+		return nil
+	}
+
+	now := p.now()
+	p.charge(now)
+	p.current = interpreter.Frames(line)
+	p.started = now
+	return nil
+}
+
+// AfterExpression notices calls returning: from then on, time is charged
+// to the caller again rather than to the last statement of the callee.
+func (p *Profiler) AfterExpression(interpreter *visitor.Interpreter, expr core.Expression, line int, value any) {
+	if p.current == nil || interpreter.CallDepth() == len(p.current)-1 {
+		return
+	}
+
+	now := p.now()
+	p.charge(now)
+	if line == 0 {
+		line = p.current[0].Line
+	}
+	p.current = interpreter.Frames(line)
+	p.started = now
+}
+
+// Stop charges the time since the last statement started. Call it once the
+// script is done, before writing reports.
+func (p *Profiler) Stop() {
+	p.charge(p.now())
+	p.current = nil
+}
+
+func (p *Profiler) charge(now time.Time) {
+	if p.current == nil {
+		return
+	}
+	elapsed := now.Sub(p.started)
+
+	// Frames come innermost first, stacks are kept outermost first. Only
+	// the innermost frame keeps its line, so the calls a function makes
+	// from different lines still share its frame in the flame graph.
+	functions := make([]function, len(p.current))
+	seen := map[function]bool{}
+	for index, frame := range p.current {
+		key := function{script: frame.Script, name: frame.Function}
+		functions[len(functions)-1-index] = key
+
+		spent := p.function(key)
+		if index == 0 {
+			spent.self += elapsed
+		}
+		// Recursive functions count once towards their total.
+		if !seen[key] {
+			seen[key] = true
+			spent.total += elapsed
+		}
+	}
+
+	key := fmt.Sprintf("%q:%d", functions, p.current[0].Line)
+	if p.stacks[key] == nil {
+		p.stacks[key] = &stack{functions: functions, line: p.current[0].Line}
+	}
+	p.stacks[key].elapsed += elapsed
+	p.lines[location{script: p.current[0].Script, line: p.current[0].Line}] += elapsed
+}
+
+func (p *Profiler) function(key function) *functionTime {
+	if p.functions[key] == nil {
+		p.functions[key] = &functionTime{}
+	}
+	return p.functions[key]
+}
+
+// names returns how reports show every function: by its name, followed by
+// its script when functions of several scripts share that name.
+func (p *Profiler) names() map[function]string {
+	scripts := map[string]int{}
+	for key := range p.functions {
+		scripts[key.name]++
+	}
+
+	names := map[function]string{}
+	for key := range p.functions {
+		names[key] = key.name
+		if scripts[key.name] > 1 {
+			names[key] = fmt.Sprintf("%s (%s)", key.name, key.script)
+		}
+	}
+	return names
+}
+
+// WriteFolded writes the collapsed stacks flame graph tools read: one line
+// per stack, `function;function;function:line microseconds`, the innermost
+// function followed by the line it was running. Times are rounded to the
+// nearest microsecond.
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	names := p.names()
+	stacks := make([]string, 0, len(p.stacks))
+	for _, stack := range p.stacks {
+		frames := make([]string, len(stack.functions))
+		for index, key := range stack.functions {
+			frames[index] = names[key]
+		}
+		frames[len(frames)-1] = fmt.Sprintf("%s:%d", frames[len(frames)-1], stack.line)
+		stacks = append(stacks, fmt.Sprintf("%s %d", strings.Join(frames, ";"), stack.elapsed.Round(time.Microsecond).Microseconds()))
+	}
+	sort.Strings(stacks)
+
+	for _, stack := range stacks {
+		if _, err := fmt.Fprintln(w, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSummary writes the top hottest lines and the time of every function,
+// the slowest first.
+func (p *Profiler) WriteSummary(w io.Writer, top int) error {
+	var total time.Duration
+	lines := make([]location, 0, len(p.lines))
+	for line, elapsed := range p.lines {
+		lines = append(lines, line)
+		total += elapsed
+	}
+	sort.Slice(lines, func(a, b int) bool {
+		if p.lines[lines[a]] != p.lines[lines[b]] {
+			return p.lines[lines[a]] > p.lines[lines[b]]
+		}
+		if lines[a].script != lines[b].script {
+			return lines[a].script < lines[b].script
+		}
+		return lines[a].line < lines[b].line
+	})
+	if len(lines) > top {
+		lines = lines[:top]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hottest lines (total %v):\n", total)
+	for _, line := range lines {
+		fmt.Fprintf(&b, "  %12v %6.2f%%  %s:%d\n", p.lines[line], percent(p.lines[line], total), line.script, line.line)
+	}
+
+	names := p.names()
+	functions := make([]function, 0, len(p.functions))
+	for key := range p.functions {
+		functions = append(functions, key)
+	}
+	sort.Slice(functions, func(a, b int) bool {
+		if p.functions[functions[a]].total != p.functions[functions[b]].total {
+			return p.functions[functions[a]].total > p.functions[functions[b]].total
+		}
+		return names[functions[a]] < names[functions[b]]
+	})
+
+	fmt.Fprintf(&b, "Functions (self, total):\n")
+	for _, key := range functions {
+		spent := p.functions[key]
+		fmt.Fprintf(&b, "  %12v %12v  %s\n", spent.self, spent.total, names[key])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func percent(part time.Duration, total time.Duration) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
@@ -0,0 +1,168 @@
+package profiler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// profile runs source under a profiler whose clock moves by step every time
+// it is read.
+func profile(t *testing.T, source string, step time.Duration) *Profiler {
+	t.Helper()
+	return profileScripts(t, step, "script.lox", source)
+}
+
+// profileScripts runs scripts, given as name and source pairs, one after the
+// other in the same interpreter, as `run` does with several files.
+func profileScripts(t *testing.T, step time.Duration, scripts ...string) *Profiler {
+	t.Helper()
+
+	clock := time.Unix(0, 0)
+	profiler := CreateProfiler()
+	profiler.now = func() time.Time {
+		clock = clock.Add(step)
+		return clock
+	}
+
+	interpreter := visitor.CreateInterpreter()
+	interpreter.SetOutput(&bytes.Buffer{})
+	interpreter.SetHook(profiler)
+	for index := 0; index < len(scripts); index += 2 {
+		tokens, errs := scanner.ScanFile([]byte(scripts[index+1]))
+		if len(errs) != 0 {
+			t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+		}
+		statements, parseErr := parser.Parse(tokens)
+		if parseErr != nil {
+			t.Fatalf("was not expecting any errors, but got: %v", parseErr.Err)
+		}
+
+		interpreter.SetScriptName(scripts[index])
+		for _, statement := range statements {
+			if _, err := interpreter.Interpret(statement); err.Err != nil {
+				t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+			}
+		}
+	}
+	profiler.Stop()
+	return profiler
+}
+
+const program = `fun square(x) {
+  return x * x;
+}
+var total = 0;
+total = total + square(2);
+print total;
+`
+
+func TestFoldedStacks(t *testing.T) {
+	var out bytes.Buffer
+	if err := profile(t, program, time.Millisecond).WriteFolded(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<script>:1 1000
+<script>:4 1000
+<script>:5 2000
+<script>:6 1000
+<script>;square:2 1000
+`
+	if out.String() != expected {
+		t.Fatalf("expecting:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestFoldedStacksNameCallersByFunction(t *testing.T) {
+	source := `fun square(x) {
+  return x * x;
+}
+fun sum(a, b) {
+  var total = square(a);
+  return total + square(b);
+}
+sum(1, 2);
+`
+	var out bytes.Buffer
+	if err := profile(t, source, 900*time.Nanosecond).WriteFolded(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	// both calls of square share one stack, and their 1.8µs rounds to 2 rather
+	// than being truncated to 1
+	if !strings.Contains(out.String(), "\n<script>;sum;square:2 2\n") {
+		t.Fatalf("expecting one stack for square called from sum, but got:\n%s", out.String())
+	}
+}
+
+func TestSummaryListsHottestLinesAndFunctions(t *testing.T) {
+	var out bytes.Buffer
+	if err := profile(t, program, time.Millisecond).WriteSummary(&out, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"Hottest lines (total 6ms):",
+		"           2ms  33.33%  script.lox:5",
+		"           1ms  16.67%  script.lox:1",
+		"Functions (self, total):",
+		"           5ms          6ms  <script>",
+		"           1ms          1ms  square",
+	}
+	if found := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(found, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expecting:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), out.String())
+	}
+}
+
+func TestFunctionsOfTheSameNameInDifferentScripts(t *testing.T) {
+	first := `fun helper() {
+  return 1;
+}
+helper();
+`
+	second := `fun helper() {
+  var x = 1;
+  return x;
+}
+fun other() {
+  return 2;
+}
+helper();
+other();
+`
+	p := profileScripts(t, time.Millisecond, "a.lox", first, "b.lox", second)
+
+	var summary bytes.Buffer
+	if err := p.WriteSummary(&summary, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"           1ms          1ms  helper (a.lox)\n",
+		"           2ms          2ms  helper (b.lox)\n",
+		"           1ms          1ms  other\n",
+	} {
+		if !strings.Contains(summary.String(), expected) {
+			t.Fatalf("expecting the summary to contain %q, but got:\n%s", expected, summary.String())
+		}
+	}
+
+	var folded bytes.Buffer
+	if err := p.WriteFolded(&folded); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"\n<script> (a.lox);helper (a.lox):2 1000\n",
+		"\n<script> (b.lox);helper (b.lox):3 1000\n",
+		"\n<script> (b.lox);other:6 1000\n",
+	} {
+		if !strings.Contains(folded.String(), expected) {
+			t.Fatalf("expecting the folded stacks to contain %q, but got:\n%s", expected, folded.String())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/coverage"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/profiler"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/tracer"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// program is one source of the program run: a file, standard input or the
+// code given with -e.
+type program struct {
+	name       string
+	source     []byte
+	statements []core.Statement
+}
+
+// runCommand implements `run [--trace [--trace-format=text|jsonl]
+// [--trace-output=file]] [--profile=file] [--coverage=file]
+// [--no-fs | --fs-root=dir] [--decimal-scale=n] [--decimal-rounding=mode]
+// [--max-steps=n] [--max-memory=bytes] [--max-call-depth=n]
+// (-e code | <file>...)`. Several files make up one program, run one after
+// the other in the same globals, and `-` reads standard input. The trace
+// goes to stderr unless a file is given. Profiling writes collapsed stacks
+// to its file and a summary of the hottest lines to stderr, and coverage
+// writes a profile for the coverage command. --no-fs and --fs-root take
+// away or confine the file access of scripts. The decimal flags set how
+// decimal divisions round until a script calls setRounding, and the max
+// flags set the interpreter's limits, where 0 means no limit.
+func runCommand(args []string) {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	trace := flags.Bool("trace", false, "log every statement and expression executed")
+	traceFormat := flags.String("trace-format", "text", "trace format: text or jsonl")
+	traceOutput := flags.String("trace-output", "", "file to write the trace to instead of stderr")
+	profile := flags.String("profile", "", "file to write collapsed stacks of the time spent to")
+	cover := flags.String("coverage", "", "file to write the statements and branches run to")
+	code := flags.String("e", "", "code to run instead of files")
+	noFileAccess := flags.Bool("no-fs", false, "disable the file natives and imports")
+	fileRoot := flags.String("fs-root", "", "directory the file natives and imports are confined to")
+	decimalScale := flags.Int("decimal-scale", decimal.DefaultContext.Scale, "fractional digits kept by decimal divisions")
+	decimalRounding := flags.String("decimal-rounding", decimal.DefaultContext.Rounding.String(), "rounding of decimal divisions: half-even, half-up, half-down, up, down, ceiling or floor")
+	maxSteps := flags.Int("max-steps", visitor.DefaultLimits.MaxSteps, "statements and expressions a script may evaluate, 0 for no limit")
+	maxMemory := flags.Int("max-memory", visitor.DefaultLimits.MaxMemory, "bytes the variables of a script may hold at once, 0 for no limit")
+	maxCallDepth := flags.Int("max-call-depth", visitor.DefaultLimits.MaxCallDepth, "nested function calls allowed, 0 for no limit")
+	flags.Parse(args)
+
+	if (*code == "") == (flags.NArg() == 0) {
+		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh run [--trace] (-e <code> | <filename>...)")
+		os.Exit(1)
+	}
+
+	// Every source is parsed before anything runs, so a syntax error in the
+	// last file doesn't leave the program half run.
+	programs := []program{}
+	if *code != "" {
+		programs = append(programs, parseProgram(inlineName, []byte(*code)))
+	}
+	for _, filename := range flags.Args() {
+		name := filename
+		if filename == stdinName {
+			name = stdinScriptName
+		}
+		programs = append(programs, parseProgram(name, readSource(filename)))
+	}
+
+	interpreter := visitor.CreateInterpreter()
+	interpreter.SetFileAccess(visitor.FileAccess{Disabled: *noFileAccess, Root: *fileRoot})
+
+	rounding, roundingErr := decimal.ParseRoundingMode(*decimalRounding)
+	if roundingErr != nil {
+		fmt.Fprintf(os.Stderr, "Unknown decimal rounding mode: %s\n", *decimalRounding)
+		os.Exit(1)
+	}
+	if *decimalScale < 0 || *decimalScale > decimal.MaxScale {
+		fmt.Fprintf(os.Stderr, "Decimal scale must be 0 to %d: %d\n", decimal.MaxScale, *decimalScale)
+		os.Exit(1)
+	}
+	interpreter.SetDecimalContext(decimal.Context{Scale: *decimalScale, Rounding: rounding})
+
+	if *maxSteps < 0 || *maxMemory < 0 || *maxCallDepth < 0 {
+		fmt.Fprintln(os.Stderr, "Limits must not be negative")
+		os.Exit(1)
+	}
+	interpreter.SetLimits(visitor.Limits{MaxSteps: *maxSteps, MaxMemory: *maxMemory, MaxCallDepth: *maxCallDepth})
+
+	// finishers write out whatever the run collected, also when it fails.
+	hooks := []visitor.Hook{}
+	finishers := []func(){}
+	if *trace {
+		format := tracer.Format(*traceFormat)
+		if format != tracer.Text && format != tracer.JSONLines {
+			fmt.Fprintf(os.Stderr, "Unknown trace format: %s\n", *traceFormat)
+			os.Exit(1)
+		}
+
+		var output io.Writer = os.Stderr
+		if *traceOutput != "" {
+			file, err := os.Create(*traceOutput)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating trace file: %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			output = file
+		}
+
+		t := tracer.CreateTracer(output, format)
+		hooks = append(hooks, t)
+		finishers = append(finishers, func() {
+			if err := t.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing trace: %v\n", err)
+			}
+		})
+	}
+
+	if *profile != "" {
+		p := profiler.CreateProfiler()
+		hooks = append(hooks, p)
+		finishers = append(finishers, func() {
+			p.Stop()
+			if err := writeProfile(p, *profile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing profile: %v\n", err)
+			}
+			p.WriteSummary(os.Stderr, 10)
+		})
+	}
+
+	if *cover != "" {
+		collectors := []*coverage.Collector{}
+		for _, program := range programs {
+			collector := coverage.CreateCollector(program.name, program.statements)
+			if program.name == inlineName || program.name == stdinScriptName {
+				collector.KeepSource(program.source)
+			}
+			collectors = append(collectors, collector)
+			hooks = append(hooks, collector)
+		}
+		finishers = append(finishers, func() {
+			profiles := []coverage.Profile{}
+			for _, collector := range collectors {
+				profiles = append(profiles, collector.Profile())
+			}
+			if err := writeCoverage(coverage.Merge(profiles...), *cover); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing coverage: %v\n", err)
+			}
+		})
+	}
+
+	if len(hooks) > 0 {
+		interpreter.SetHook(visitor.CombineHooks(hooks...))
+	}
+	finish := func() {
+		for _, finisher := range finishers {
+			finisher()
+		}
+	}
+
+	for _, program := range programs {
+		interpreter.SetScriptName(program.name)
+		for _, statement := range program.statements {
+			if _, err := interpreter.Interpret(statement); err.Err != nil {
+				finish()
+				printErrorAndExit(&err)
+			}
+		}
+	}
+	finish()
+}
+
+// Script names shown in stack traces for code that is not read from a file.
+const (
+	inlineName      = "<inline>"
+	stdinScriptName = "<stdin>"
+)
+
+func parseProgram(name string, source []byte) program {
+	statements, err := parser.Parse(scan(source, false))
+	printErrorAndExit(err)
+	return program{name: name, source: source, statements: statements}
+}
+
+func writeProfile(p *profiler.Profiler, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return p.WriteFolded(file)
+}
+
+func writeCoverage(profile coverage.Profile, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return profile.Write(file)
+}
@@ -3,24 +3,64 @@ package scanner
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
 )
 
 var tokens []core.Token
+var comments []core.Comment
 var errors []core.Error
 var position int
 var contents []byte
 var endOfFile int
 var line int
 
+// lineStart is the position where the current line begins, and tokenColumn
+// the column where the token being scanned begins.
+var lineStart int
+var tokenColumn int
+var positioned int
+
 func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
+	scannedTokens, _, scanErrors := ScanFileWithComments(fileContents)
+	return scannedTokens, scanErrors
+}
+
+// skipShebang passes over a `#!` line starting the file, which lets scripts
+// be run as executables. It is kept as a comment so tools such as the
+// formatter preserve it.
+func skipShebang() {
+	if !strings.HasPrefix(string(contents), "#!") {
+		return
+	}
+
+	for position < endOfFile && contents[position] != '\n' {
+		position++
+	}
+	text := strings.TrimRight(string(contents[:position]), " \t\r")
+	comments = append(comments, core.Comment{Text: text, Line: 1})
+}
+
+// ScanFileWithComments is ScanFile for tools that need the comments too.
+func ScanFileWithComments(fileContents []byte) ([]core.Token, []core.Comment, []core.Error) {
+	tokens = []core.Token{}
+	comments = []core.Comment{}
+	errors = nil
+	position = 0
 	contents = fileContents
 	endOfFile = len(contents)
 	line = 1
+	lineStart = 0
+	positioned = 0
+
+	skipShebang()
 
 	for position < endOfFile {
+		setColumns()
+		tokenColumn = position - lineStart
 		character := rune(contents[position])
 
 		switch character {
@@ -33,17 +73,66 @@ func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
 		case '}':
 			tokens = append(tokens, core.Token{Type: core.RIGHT_BRACE, Lexeme: "}", Literal: nil, Line: line})
 		case '*':
-			tokens = append(tokens, core.Token{Type: core.STAR, Lexeme: "*", Literal: nil, Line: line})
+			if nextRuneEquals('*') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.STAR_STAR, Lexeme: "**", Literal: nil, Line: line})
+			} else if nextRuneEquals('=') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.STAR_EQUAL, Lexeme: "*=", Literal: nil, Line: line})
+			} else {
+				tokens = append(tokens, core.Token{Type: core.STAR, Lexeme: "*", Literal: nil, Line: line})
+			}
+		case '%':
+			tokens = append(tokens, core.Token{Type: core.PERCENT, Lexeme: "%", Literal: nil, Line: line})
+		case '&':
+			tokens = append(tokens, core.Token{Type: core.AMPERSAND, Lexeme: "&", Literal: nil, Line: line})
+		case '|':
+			tokens = append(tokens, core.Token{Type: core.PIPE, Lexeme: "|", Literal: nil, Line: line})
+		case '^':
+			tokens = append(tokens, core.Token{Type: core.CARET, Lexeme: "^", Literal: nil, Line: line})
+		case '~':
+			// "//" already starts a comment, so integer division is spelled "~/"
+			if nextRuneEquals('/') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.TILDE_SLASH, Lexeme: "~/", Literal: nil, Line: line})
+			} else {
+				tokens = append(tokens, core.Token{Type: core.TILDE, Lexeme: "~", Literal: nil, Line: line})
+			}
 		case '.':
 			tokens = append(tokens, core.Token{Type: core.DOT, Lexeme: ".", Literal: nil, Line: line})
 		case ',':
 			tokens = append(tokens, core.Token{Type: core.COMMA, Lexeme: ",", Literal: nil, Line: line})
 		case '+':
-			tokens = append(tokens, core.Token{Type: core.PLUS, Lexeme: "+", Literal: nil, Line: line})
+			if nextRuneEquals('+') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.PLUS_PLUS, Lexeme: "++", Literal: nil, Line: line})
+			} else if nextRuneEquals('=') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.PLUS_EQUAL, Lexeme: "+=", Literal: nil, Line: line})
+			} else {
+				tokens = append(tokens, core.Token{Type: core.PLUS, Lexeme: "+", Literal: nil, Line: line})
+			}
 		case '-':
-			tokens = append(tokens, core.Token{Type: core.MINUS, Lexeme: "-", Literal: nil, Line: line})
+			if nextRuneEquals('-') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.MINUS_MINUS, Lexeme: "--", Literal: nil, Line: line})
+			} else if nextRuneEquals('=') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.MINUS_EQUAL, Lexeme: "-=", Literal: nil, Line: line})
+			} else {
+				tokens = append(tokens, core.Token{Type: core.MINUS, Lexeme: "-", Literal: nil, Line: line})
+			}
 		case ';':
 			tokens = append(tokens, core.Token{Type: core.SEMICOLON, Lexeme: ";", Literal: nil, Line: line})
+		case ':':
+			tokens = append(tokens, core.Token{Type: core.COLON, Lexeme: ":", Literal: nil, Line: line})
+		case '?':
+			if nextRuneEquals('?') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.QUESTION_QUESTION, Lexeme: "??", Literal: nil, Line: line})
+			} else {
+				tokens = append(tokens, core.Token{Type: core.QUESTION, Lexeme: "?", Literal: nil, Line: line})
+			}
 		case '=':
 			if nextRuneEquals('=') {
 				advanceCursor()
@@ -62,6 +151,9 @@ func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
 			if nextRuneEquals('=') {
 				advanceCursor()
 				tokens = append(tokens, core.Token{Type: core.LESS_EQUAL, Lexeme: "<=", Literal: nil, Line: line})
+			} else if nextRuneEquals('<') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.LESS_LESS, Lexeme: "<<", Literal: nil, Line: line})
 			} else {
 				tokens = append(tokens, core.Token{Type: core.LESS, Lexeme: "<", Literal: nil, Line: line})
 			}
@@ -69,6 +161,9 @@ func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
 			if nextRuneEquals('=') {
 				advanceCursor()
 				tokens = append(tokens, core.Token{Type: core.GREATER_EQUAL, Lexeme: ">=", Literal: nil, Line: line})
+			} else if nextRuneEquals('>') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.GREATER_GREATER, Lexeme: ">>", Literal: nil, Line: line})
 			} else {
 				tokens = append(tokens, core.Token{Type: core.GREATER, Lexeme: ">", Literal: nil, Line: line})
 			}
@@ -76,8 +171,10 @@ func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
 			// ignore whitespaces
 		case '\n':
 			line++
+			lineStart = position + 1
 		case '/':
 			if nextRuneEquals('/') {
+				startPosition := position
 				advanceCursor()
 
 				for !currentRuneEquals('\n') {
@@ -87,7 +184,15 @@ func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
 					}
 				}
 
+				text := strings.TrimRight(string(contents[startPosition:position]), " \t\r")
+				trailing := len(tokens) > 0 && tokens[len(tokens)-1].Line == line
+				comments = append(comments, core.Comment{Text: text, Line: line, Trailing: trailing})
+
 				line++
+				lineStart = position + 1
+			} else if nextRuneEquals('=') {
+				advanceCursor()
+				tokens = append(tokens, core.Token{Type: core.SLASH_EQUAL, Lexeme: "/=", Literal: nil, Line: line})
 			} else {
 				tokens = append(tokens, core.Token{Type: core.SLASH, Lexeme: "/", Literal: nil, Line: line})
 			}
@@ -127,9 +232,18 @@ func ScanFile(fileContents []byte) ([]core.Token, []core.Error) {
 		advanceCursor()
 	}
 
-	tokens = append(tokens, core.Token{Type: core.EOF, Lexeme: "", Literal: nil, Line: line})
+	setColumns()
+	tokens = append(tokens, core.Token{Type: core.EOF, Lexeme: "", Literal: nil, Line: line, Column: position - lineStart})
 
-	return tokens, errors
+	return tokens, comments, errors
+}
+
+// setColumns gives the tokens added since the last call the column where
+// scanning them started.
+func setColumns() {
+	for ; positioned < len(tokens); positioned++ {
+		tokens[positioned].Column = tokenColumn
+	}
 }
 
 func reportError(line int, exitCode int, err error) {
@@ -180,6 +294,20 @@ func tokenizeNumber() {
 		}
 	}
 
+	// a trailing 'd' marks an arbitrary-precision decimal literal, e.g. 19.99d
+	if currentRune() == 'd' && !isAlphaNumeric(nextRune()) {
+		advanceCursor()
+
+		lexeme := string(contents[startPosition:position])
+		literal, err := decimal.Parse(lexeme)
+		if err != nil {
+			reportError(line, 65, err)
+			return
+		}
+		tokens = append(tokens, core.Token{Type: core.DECIMAL, Lexeme: lexeme, Literal: literal, Line: line})
+		return
+	}
+
 	lexeme := string(contents[startPosition:position])
 	literal, err := strconv.ParseFloat(lexeme, 64)
 	if err != nil {
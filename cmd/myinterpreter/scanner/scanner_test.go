@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+func TestTokensCarryColumns(t *testing.T) {
+	tokens, errs := ScanFile([]byte("var answer = 42;\n  print \"hi\" + 1.5d; // done\nx"))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+
+	expected := []struct {
+		lexeme string
+		line   int
+		column int
+	}{
+		{"var", 1, 0}, {"answer", 1, 4}, {"=", 1, 11}, {"42", 1, 13}, {";", 1, 15},
+		{"print", 2, 2}, {`"hi"`, 2, 8}, {"+", 2, 13}, {"1.5d", 2, 15}, {";", 2, 19},
+		{"x", 3, 0}, {"", 3, 1},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("expecting %d tokens, but got: %d", len(expected), len(tokens))
+	}
+	for index, token := range tokens {
+		e := expected[index]
+		if token.Lexeme != e.lexeme || token.Line != e.line || token.Column != e.column {
+			t.Fatalf("expecting %q at %d:%d, but got: %q at %d:%d", e.lexeme, e.line, e.column, token.Lexeme, token.Line, token.Column)
+		}
+	}
+}
+
+func TestShebangLineIsSkipped(t *testing.T) {
+	tokens, comments, errs := ScanFileWithComments([]byte("#!/usr/bin/env lox run\nprint 1;"))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+
+	if tokens[0].Lexeme != "print" || tokens[0].Line != 2 {
+		t.Fatalf("expecting the first token to be print on line 2, but got: %q on line %d", tokens[0].Lexeme, tokens[0].Line)
+	}
+	if len(comments) != 1 || comments[0].Text != "#!/usr/bin/env lox run" {
+		t.Fatalf("expecting the shebang to be kept as a comment, but got: %v", comments)
+	}
+
+	if _, errs := ScanFile([]byte("print 1;\n#!x")); len(errs) == 0 {
+		t.Fatal("expecting a shebang after the first line to be an error")
+	}
+}
+
+func TestDecimalLiterals(t *testing.T) {
+	tokens, errs := ScanFile([]byte("19.99d 1d 2.5 7dx"))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+
+	expected := []struct {
+		tokenType string
+		lexeme    string
+		literal   string
+	}{
+		{core.DECIMAL, "19.99d", "19.99"},
+		{core.DECIMAL, "1d", "1"},
+		{core.NUMBER, "2.5", "2.5"},
+		{core.NUMBER, "7", "7"},
+		{core.IDENTIFIER, "dx", "<nil>"},
+	}
+	for index, e := range expected {
+		token := tokens[index]
+		if token.Type != e.tokenType || token.Lexeme != e.lexeme || fmt.Sprint(token.Literal) != e.literal {
+			t.Fatalf("expecting %s %q %s, but got: %s %q %v", e.tokenType, e.lexeme, e.literal, token.Type, token.Lexeme, token.Literal)
+		}
+	}
+	if _, isDecimal := tokens[0].Literal.(decimal.Decimal); !isDecimal {
+		t.Fatalf("expecting a decimal literal, but got: %T", tokens[0].Literal)
+	}
+}
+
+func TestIntegerDivisionDoesNotClashWithComments(t *testing.T) {
+	cases := map[string]string{
+		"a ~/ b":                "IDENTIFIER TILDE_SLASH IDENTIFIER",
+		"7 ~/ 2;":               "NUMBER TILDE_SLASH NUMBER SEMICOLON",
+		"~a":                    "TILDE IDENTIFIER",
+		"~ /":                   "TILDE SLASH",
+		"var x = 1 // note\n;":  "VAR IDENTIFIER EQUAL NUMBER SEMICOLON",
+		"(2 // half\n+ 1)":      "LEFT_PAREN NUMBER PLUS NUMBER RIGHT_PAREN",
+		"a // b":                "IDENTIFIER",
+		"f(x) // 2":             "IDENTIFIER LEFT_PAREN IDENTIFIER RIGHT_PAREN",
+		"if (a) // note\nb;":    "IF LEFT_PAREN IDENTIFIER RIGHT_PAREN IDENTIFIER SEMICOLON",
+		"print 7 ~/ 2; // note": "PRINT NUMBER TILDE_SLASH NUMBER SEMICOLON",
+	}
+
+	for source, expected := range cases {
+		tokens, errs := ScanFile([]byte(source))
+		if len(errs) != 0 {
+			t.Fatalf("%q: was not expecting any errors, but got: %v", source, errs[0].Err)
+		}
+
+		types := []string{}
+		for _, token := range tokens[:len(tokens)-1] {
+			types = append(types, token.Type)
+		}
+		if got := strings.Join(types, " "); got != expected {
+			t.Fatalf("%q: expecting %s, but got: %s", source, expected, got)
+		}
+	}
+}
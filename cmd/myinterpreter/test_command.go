@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/testrunner"
+)
+
+// testCommand implements `test [--run=pattern] [--junit=file] <dir>`. It runs
+// the tests of every `*_test.lox` file under dir, prints a line per failing
+// test with its output, then a summary per file, and exits with 1 when any
+// test failed.
+func testCommand(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	run := flags.String("run", "", "run only the tests whose name matches this regular expression")
+	junit := flags.String("junit", "", "file to write a JUnit XML report to")
+	verbose := flags.Bool("v", false, "list every test, not only the failing ones")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh test [--run=pattern] [--junit=file] <dir>")
+		os.Exit(1)
+	}
+
+	var pattern *regexp.Regexp
+	if *run != "" {
+		var err error
+		if pattern, err = regexp.Compile(*run); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --run pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	files, err := testrunner.Discover(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	all := []testrunner.Result{}
+	failed := 0
+	for _, file := range files {
+		results := testrunner.RunFile(file, pattern)
+		fileFailed := 0
+		for _, result := range results {
+			if result.Passed() {
+				if *verbose {
+					fmt.Printf("--- PASS: %s (%.3fs)\n", result.Name, result.Duration.Seconds())
+				}
+				continue
+			}
+
+			fileFailed++
+			name := result.Name
+			if name == "" {
+				name = "<load>"
+			}
+			fmt.Printf("--- FAIL: %s (%.3fs)\n", name, result.Duration.Seconds())
+			fmt.Println(indent(result.Failure))
+			if result.Output != "" {
+				fmt.Println(indent(strings.TrimSuffix(result.Output, "\n")))
+			}
+		}
+
+		if fileFailed > 0 {
+			fmt.Printf("FAIL\t%s\t%d of %d failed\n", file, fileFailed, len(results))
+		} else {
+			fmt.Printf("ok\t%s\t%d passed\n", file, len(results))
+		}
+		failed += fileFailed
+		all = append(all, results...)
+	}
+
+	if *junit != "" {
+		writeReport(*junit, func(file *os.File) error { return testrunner.WriteJUnit(file, all) })
+	}
+
+	if len(files) == 0 {
+		fmt.Println("no test files")
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(text, "\n", "\n    ")
+}
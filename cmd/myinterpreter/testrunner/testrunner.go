@@ -0,0 +1,157 @@
+// Package testrunner runs tests written in Lox. Test files are named
+// `*_test.lox`; their tests are the top-level functions whose name starts
+// with `test` and the functions registered with `test(name, function)`.
+package testrunner
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// FileSuffix marks the files holding tests.
+const FileSuffix = "_test.lox"
+
+// Result is the outcome of one test. A file that fails to load is reported
+// as a single failed result without a name.
+type Result struct {
+	File     string
+	Name     string
+	Failure  string
+	Output   string
+	Duration time.Duration
+}
+
+func (r Result) Passed() bool {
+	return r.Failure == ""
+}
+
+// test is a registered test function.
+type test struct {
+	name     string
+	function visitor.Callable
+}
+
+// Discover returns the test files under dir, sorted.
+func Discover(dir string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), FileSuffix) {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	sort.Strings(files)
+	return files, err
+}
+
+// RunFile runs the tests of a file whose name matches pattern, or all of
+// them when pattern is nil. Every test runs in an interpreter of its own,
+// which first runs the top level of the file.
+func RunFile(path string, pattern *regexp.Regexp) []Result {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return []Result{{File: path, Failure: fmt.Sprintf("Error reading file: %v", err)}}
+	}
+
+	tokens, errs := scanner.ScanFile(source)
+	if len(errs) != 0 {
+		return []Result{{File: path, Failure: describe(path, errs[0])}}
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		return []Result{{File: path, Failure: describe(path, *parseErr)}}
+	}
+
+	var output bytes.Buffer
+	_, tests, loadErr := load(path, statements, &output)
+	if loadErr.Err != nil {
+		return []Result{{File: path, Failure: describe(path, loadErr), Output: output.String()}}
+	}
+
+	results := []Result{}
+	for index, found := range tests {
+		if pattern != nil && !pattern.MatchString(found.name) {
+			continue
+		}
+		results = append(results, run(path, statements, index, found.name))
+	}
+	return results
+}
+
+// load runs the top level of a test file in a new interpreter and returns
+// it with the file's tests, in the order they were declared or registered.
+func load(path string, statements []core.Statement, output *bytes.Buffer) (*visitor.Interpreter, []test, core.Error) {
+	tests := []test{}
+
+	interpreter := visitor.CreateInterpreter()
+	interpreter.SetScriptName(path)
+	interpreter.SetOutput(output)
+	interpreter.DefineAssertions()
+	interpreter.Define("test", visitor.CreateNativeFunction("test", 2, func(interpreter *visitor.Interpreter, arguments []any) (any, error) {
+		name, ok := arguments[0].(string)
+		function, isCallable := arguments[1].(visitor.Callable)
+		if !ok || !isCallable || function.Arity() != 0 {
+			return nil, fmt.Errorf("test expects a name and a function without parameters.")
+		}
+		tests = append(tests, test{name: name, function: function})
+		return nil, nil
+	}))
+
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			return nil, nil, err
+		}
+
+		if function, ok := statement.(core.FunctionStmt); ok && strings.HasPrefix(function.Name.Lexeme, "test") && len(function.Params) == 0 {
+			value, _ := interpreter.Global(function.Name.Lexeme)
+			tests = append(tests, test{name: function.Name.Lexeme, function: value.(visitor.Callable)})
+		}
+	}
+
+	return &interpreter, tests, core.Error{}
+}
+
+// run runs the test at index of a freshly loaded file.
+func run(path string, statements []core.Statement, index int, name string) Result {
+	start := time.Now()
+	var output bytes.Buffer
+	result := Result{File: path, Name: name}
+
+	interpreter, tests, err := load(path, statements, &output)
+	if err.Err == nil {
+		_, err = interpreter.Call(tests[index].function, nil)
+	}
+	if err.Err != nil {
+		result.Failure = describe(path, err)
+	}
+
+	result.Output = output.String()
+	result.Duration = time.Since(start)
+	return result
+}
+
+// describe formats an error the way the run command prints it, with its
+// stack trace.
+func describe(path string, err core.Error) string {
+	message := fmt.Sprintf("%s:%d: %v", path, err.Line, err.Err)
+	if len(err.Trace) > 0 {
+		message += "\n" + err.StackTrace()
+	}
+	return message
+}
@@ -0,0 +1,167 @@
+package testrunner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const suite = `var counter = 0;
+
+fun testIsolated() {
+  counter = counter + 1;
+  assertEqual(counter, 1);
+}
+
+fun testAgainIsolated() {
+  counter = counter + 1;
+  assertEqual(counter, 1);
+}
+
+fun failing() {
+  print "checking";
+  assert(1 > 2, "one is not greater than two");
+}
+test("failing assertion", failing);
+
+fun throwing() {
+  throw Error("boom");
+}
+fun catchesErrors() {
+  var error = assertThrows(throwing);
+  assertEqual(error.message, "boom");
+}
+test("assertThrows", catchesErrors);
+
+fun notThrowing() {}
+fun missingError() {
+  assertThrows(notThrowing);
+}
+test("missing error", missingError);
+`
+
+func writeFile(t *testing.T, dir string, name string, source string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// outcomes maps the name of every test to its failure, empty when it passed.
+func outcomes(results []Result) map[string]string {
+	found := map[string]string{}
+	for _, result := range results {
+		found[result.Name] = result.Failure
+	}
+	return found
+}
+
+func TestRunFile(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "suite_test.lox", suite)
+	results := RunFile(path, nil)
+
+	names := []string{}
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	expected := []string{"testIsolated", "testAgainIsolated", "failing assertion", "assertThrows", "missing error"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expecting tests %v, but got: %v", expected, names)
+	}
+
+	found := outcomes(results)
+	for _, name := range []string{"testIsolated", "testAgainIsolated", "assertThrows"} {
+		if found[name] != "" {
+			t.Fatalf("expecting %s to pass, but got: %s", name, found[name])
+		}
+	}
+	if !strings.HasPrefix(found["failing assertion"], path+":15: Assertion failed: one is not greater than two") {
+		t.Fatalf("expecting the assertion to fail at its line, but got: %s", found["failing assertion"])
+	}
+	if results[2].Output != "checking\n" {
+		t.Fatalf("expecting the output of the test to be kept, but got: %q", results[2].Output)
+	}
+	if !strings.Contains(found["missing error"], "Expected <fn notThrowing> to throw an error.") {
+		t.Fatalf("expecting assertThrows to fail, but got: %s", found["missing error"])
+	}
+}
+
+func TestAssertWithoutMessage(t *testing.T) {
+	source := `fun passing() {
+  assert(true);
+  assert(1 < 2);
+}
+test("passing", passing);
+
+fun failing() {
+  assert(false);
+}
+test("failing", failing);
+`
+	path := writeFile(t, t.TempDir(), "assert_test.lox", source)
+	found := outcomes(RunFile(path, nil))
+
+	if found["passing"] != "" {
+		t.Fatalf("expecting assert with one argument to pass, but got: %s", found["passing"])
+	}
+	if !strings.HasPrefix(found["failing"], path+":8: Assertion failed.") {
+		t.Fatalf("expecting a default failure message, but got: %s", found["failing"])
+	}
+}
+
+func TestRunFilters(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "suite_test.lox", suite)
+	results := RunFile(path, regexp.MustCompile("Isolated$"))
+
+	if len(results) != 2 || !results[0].Passed() || !results[1].Passed() {
+		t.Fatalf("expecting the two isolated tests to pass, but got: %v", results)
+	}
+}
+
+func TestDiscoverAndLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	broken := writeFile(t, dir, "nested/broken_test.lox", "print 1 +;\n")
+	writeFile(t, dir, "helper.lox", "fun testNothing() {}\n")
+	good := writeFile(t, dir, "good_test.lox", "fun testNothing() {}\n")
+
+	files, err := Discover(dir)
+	if err != nil || !reflect.DeepEqual(files, []string{good, broken}) {
+		t.Fatalf("expecting the two test files, but got: %v (%v)", files, err)
+	}
+
+	results := RunFile(broken, nil)
+	if len(results) != 1 || results[0].Name != "" || !strings.HasPrefix(results[0].Failure, broken+":1:") {
+		t.Fatalf("expecting the file to fail to load, but got: %v", results)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "suite_test.lox", suite)
+
+	var out bytes.Buffer
+	if err := WriteJUnit(&out, RunFile(path, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	report := out.String()
+	for _, expected := range []string{
+		`<testsuites tests="5" failures="2"`,
+		`<testsuite name="` + path + `" tests="5" failures="2"`,
+		`<testcase name="failing assertion" classname="` + path + `"`,
+		`<system-out>checking&#xA;</system-out>`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Fatalf("expecting the report to contain %q, but got:\n%s", expected, report)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package tracer logs every statement a script runs and every expression it
+// evaluates, to explain how a script reached its results.
+package tracer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/formatter"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+type Format string
+
+const (
+	// Text indents each entry by its scope depth.
+	Text Format = "text"
+	// JSONLines writes each entry as a JSON object on its own line.
+	JSONLines Format = "jsonl"
+)
+
+// Entry is one traced statement or expression. Expressions are logged once
+// evaluated, so sub-expressions come before the expression containing them.
+type Entry struct {
+	Kind  string `json:"kind"`
+	Line  int    `json:"line"`
+	Depth int    `json:"depth"`
+	Code  string `json:"code"`
+	Value string `json:"value,omitempty"`
+}
+
+// Tracer is an interpreter hook writing an entry for every statement and
+// expression. Entries are buffered until Flush.
+type Tracer struct {
+	writer *bufio.Writer
+	format Format
+	// line is the last line traced, standing in for literals, which don't
+	// know theirs.
+	line int
+}
+
+func CreateTracer(writer io.Writer, format Format) *Tracer {
+	return &Tracer{writer: bufio.NewWriter(writer), format: format}
+}
+
+func (t *Tracer) BeforeStatement(interpreter *visitor.Interpreter, stmt core.Statement, line int) error {
+	if _, ok := stmt.(core.BlockStmt); ok {
+		return nil
+	}
+
+	return t.write(Entry{Kind: "statement", Line: line, Depth: interpreter.ScopeDepth(), Code: describe(stmt)})
+}
+
+func (t *Tracer) AfterExpression(interpreter *visitor.Interpreter, expr core.Expression, line int, value any) {
+	t.write(Entry{
+		Kind:  "expression",
+		Line:  line,
+		Depth: interpreter.ScopeDepth(),
+		Code:  formatter.Expression(expr),
+		Value: visitor.Stringify(value),
+	})
+}
+
+// Flush writes out the buffered entries.
+func (t *Tracer) Flush() error {
+	return t.writer.Flush()
+}
+
+func (t *Tracer) write(entry Entry) error {
+	if entry.Line == 0 {
+		entry.Line = t.line
+	}
+	t.line = entry.Line
+
+	if t.format == JSONLines {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(t.writer, "%s\n", line)
+		return err
+	}
+
+	indent := strings.Repeat("  ", entry.Depth)
+	if entry.Kind == "statement" {
+		_, err := fmt.Fprintf(t.writer, "[line %d] %s%s\n", entry.Line, indent, entry.Code)
+		return err
+	}
+	_, err := fmt.Fprintf(t.writer, "[line %d] %s  %s => %s\n", entry.Line, indent, entry.Code, entry.Value)
+	return err
+}
+
+// describe prints the head of a statement, leaving out the bodies: those are
+// traced as their own statements.
+func describe(stmt core.Statement) string {
+	switch node := stmt.(type) {
+	case core.ExpressionStmt:
+		return formatter.Expression(node.Expr) + ";"
+	case core.PrintStmt:
+		return "print " + formatter.Expression(node.Expr) + ";"
+	case core.VarStmt:
+		if node.Initializer == nil {
+			return "var " + node.Name.Lexeme + ";"
+		}
+		return "var " + node.Name.Lexeme + " = " + formatter.Expression(node.Initializer) + ";"
+	case core.FunctionStmt:
+		params := []string{}
+		for _, param := range node.Params {
+			params = append(params, param.Lexeme)
+		}
+		return fmt.Sprintf("fun %s(%s)", node.Name.Lexeme, strings.Join(params, ", "))
+	case core.ReturnStmt:
+		if node.Value == nil {
+			return "return;"
+		}
+		return "return " + formatter.Expression(node.Value) + ";"
+	case core.ThrowStmt:
+		return "throw " + formatter.Expression(node.Value) + ";"
+	case core.TryStmt:
+		return "try"
+	case core.IfStmt:
+		return "if (" + formatter.Expression(node.Condition) + ")"
+	case core.WhileStmt:
+		return "while (" + formatter.Expression(node.Condition) + ")"
+	case core.ForStmt:
+		return "for"
+	case core.ImportStmt:
+		return "import " + node.Path.Lexeme + ";"
+	}
+
+	return ""
+}
@@ -0,0 +1,80 @@
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/visitor"
+)
+
+// trace runs source with a tracer and returns what it wrote.
+func trace(t *testing.T, source string, format Format) string {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(source))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any errors, but got: %v", errs[0].Err)
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", parseErr.Err)
+	}
+
+	var out bytes.Buffer
+	tracer := CreateTracer(&out, format)
+	interpreter := visitor.CreateInterpreter()
+	interpreter.SetOutput(&bytes.Buffer{})
+	interpreter.SetHook(tracer)
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+		}
+	}
+
+	if err := tracer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestTextTraceIsIndentedByScope(t *testing.T) {
+	source := "var a = 1;\n{\n  print a + 2;\n}\n"
+	expected := `[line 1] var a = 1;
+[line 1]   1 => 1
+[line 3]   print a + 2;
+[line 3]     a => 1
+[line 3]     2 => 2
+[line 3]     a + 2 => 3
+`
+
+	if found := trace(t, source, Text); found != expected {
+		t.Fatalf("expecting:\n%s\nbut got:\n%s", expected, found)
+	}
+}
+
+func TestJSONLinesTrace(t *testing.T) {
+	source := "fun double(x) {\n  return x * 2;\n}\nvar result = double(4);\n"
+	lines := strings.Split(strings.TrimSpace(trace(t, source, JSONLines)), "\n")
+
+	entries := []Entry{}
+	for _, line := range lines {
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expecting a JSON object per line, but got: %q", line)
+		}
+		entries = append(entries, entry)
+	}
+
+	expected := Entry{Kind: "statement", Line: 2, Depth: 1, Code: "return x * 2;"}
+	if entries[4] != expected {
+		t.Fatalf("expecting %v, but got: %v", expected, entries[4])
+	}
+	last := entries[len(entries)-1]
+	if last.Kind != "expression" || last.Code != "double(4)" || last.Value != "8" || last.Line != 4 {
+		t.Fatalf("expecting the call to be traced last, but got: %v", last)
+	}
+}
@@ -0,0 +1,70 @@
+package visitor
+
+import (
+	"fmt"
+)
+
+// DefineAssertions adds the natives test scripts check their results with:
+// `assert(condition)` or `assert(condition, message)`,
+// `assertEqual(actual, expected)` and `assertThrows(function)`. A failed
+// assertion is a runtime error at the line of the call.
+func (i *Interpreter) DefineAssertions() error {
+	for _, native := range []*NativeFunction{
+		{name: "assert", arity: 2, optional: 1, function: assert},
+		{name: "assertEqual", arity: 2, function: assertEqual},
+		{name: "assertThrows", arity: 1, function: assertThrows},
+	} {
+		if err := i.Define(native.name, native); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assert(interpreter *Interpreter, arguments []any) (any, error) {
+	if !isTruthy(arguments[0]) && arguments[1] == nil {
+		return nil, fmt.Errorf("Assertion failed.")
+	}
+	if !isTruthy(arguments[0]) {
+		return nil, fmt.Errorf("Assertion failed: %s", Stringify(arguments[1]))
+	}
+	return nil, nil
+}
+
+func assertEqual(interpreter *Interpreter, arguments []any) (any, error) {
+	if !isEqual(arguments[0], arguments[1]) {
+		return nil, fmt.Errorf("Expected %s but got %s.", quote(arguments[1]), quote(arguments[0]))
+	}
+	return nil, nil
+}
+
+// assertThrows calls a function without arguments and returns what a catch
+// clause would bind for the error it raises.
+func assertThrows(interpreter *Interpreter, arguments []any) (any, error) {
+	function, ok := arguments[0].(Callable)
+	if !ok || function.Arity() != 0 {
+		return nil, fmt.Errorf("assertThrows expects a function without parameters.")
+	}
+
+	_, err := interpreter.Call(function, nil)
+	if err.Err == nil {
+		return nil, fmt.Errorf("Expected %s to throw an error.", Stringify(function))
+	}
+	if !isCatchable(err) {
+		return nil, err
+	}
+
+	value, caughtErr := interpreter.caughtValue(err)
+	if caughtErr.Err != nil {
+		return nil, caughtErr
+	}
+	return value, nil
+}
+
+// quote shows strings in quotes, so failures tell "1" and 1 apart.
+func quote(value any) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf("%q", str)
+	}
+	return Stringify(value)
+}
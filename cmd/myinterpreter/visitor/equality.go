@@ -0,0 +1,91 @@
+package visitor
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+// isEqual implements Lox `==`. It never panics, whatever the operands are:
+//
+//   - nil is only equal to nil
+//   - booleans are equal when they have the same value
+//   - numbers and decimals are equal when they have the same numeric value,
+//     so 1 == 1.0d; NaN is not equal to anything, including itself
+//   - strings are equal when they have the same content
+//   - any other value (functions, instances, collections) is only equal to
+//     itself
+//
+// Values of different kinds are never equal, so 0 != false and "1" != 1.
+func isEqual(a any, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	switch left := a.(type) {
+	case bool:
+		right, ok := b.(bool)
+		return ok && left == right
+
+	case string:
+		right, ok := b.(string)
+		return ok && left == right
+
+	case float64, decimal.Decimal:
+		return isNumberEqual(a, b)
+	}
+
+	return isSameObject(a, b)
+}
+
+func isNumberEqual(a any, b any) bool {
+	aFloat, aIsFloat := a.(float64)
+	bFloat, bIsFloat := b.(float64)
+	if aIsFloat && bIsFloat {
+		return aFloat == bFloat
+	}
+
+	_, aIsDecimal := a.(decimal.Decimal)
+	_, bIsDecimal := b.(decimal.Decimal)
+	if !(aIsFloat || aIsDecimal) || !(bIsFloat || bIsDecimal) {
+		return false
+	}
+
+	// a float that cannot become a decimal (NaN, ±Inf) can't equal one
+	if (aIsFloat && !isFinite(aFloat)) || (bIsFloat && !isFinite(bFloat)) {
+		return false
+	}
+
+	left, right, err := getMultipleDecimal(core.Token{}, a, b)
+	return err == nil && left.Cmp(right) == 0
+}
+
+// isSameObject compares reference values by identity. Go's == is only used
+// when the dynamic values are comparable, so maps, slices and structs holding
+// them can't make the comparison panic.
+func isSameObject(a any, b any) bool {
+	left := reflect.ValueOf(a)
+	right := reflect.ValueOf(b)
+	if left.Type() != right.Type() {
+		return false
+	}
+
+	switch left.Kind() {
+	case reflect.Map, reflect.Pointer, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return left.Pointer() == right.Pointer()
+	case reflect.Slice:
+		return left.Pointer() == right.Pointer() && left.Len() == right.Len()
+	}
+
+	if left.Comparable() && right.Comparable() {
+		return left.Equal(right)
+	}
+
+	return false
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
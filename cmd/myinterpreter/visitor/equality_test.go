@@ -0,0 +1,119 @@
+package visitor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+func mustDecimal(t *testing.T, literal string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.Parse(literal)
+	if err != nil {
+		t.Fatalf("was not expecting any errors parsing %s, but got: %v", literal, err)
+	}
+	return d
+}
+
+type object struct{ name string }
+
+func TestIsEqual(t *testing.T) {
+	instance := &object{name: "a"}
+	collection := map[string]any{"a": 1.0}
+	list := []any{1.0, 2.0}
+
+	cases := []struct {
+		name     string
+		a, b     any
+		expected bool
+	}{
+		{"nil equals nil", nil, nil, true},
+		{"nil does not equal number", nil, 5.0, false},
+		{"number does not equal nil", 5.0, nil, false},
+		{"nil does not equal false", nil, false, false},
+		{"nil does not equal empty string", nil, "", false},
+		{"nil does not equal zero", nil, 0.0, false},
+
+		{"true equals true", true, true, true},
+		{"false equals false", false, false, true},
+		{"true does not equal false", true, false, false},
+		{"false does not equal zero", false, 0.0, false},
+		{"true does not equal one", true, 1.0, false},
+
+		{"numbers by value", 1.5, 1.5, true},
+		{"different numbers", 1.0, 2.0, false},
+		{"positive and negative zero", 0.0, math.Copysign(0, -1), true},
+		{"NaN does not equal NaN", math.NaN(), math.NaN(), false},
+		{"NaN does not equal number", math.NaN(), 1.0, false},
+		{"infinities", math.Inf(1), math.Inf(1), true},
+		{"opposite infinities", math.Inf(1), math.Inf(-1), false},
+
+		{"decimals by value", mustDecimal(t, "1.10d"), mustDecimal(t, "1.1d"), true},
+		{"different decimals", mustDecimal(t, "1.10d"), mustDecimal(t, "1.11d"), false},
+		{"decimal equals number", mustDecimal(t, "0.5d"), 0.5, true},
+		{"number equals decimal", 2.0, mustDecimal(t, "2d"), true},
+		{"decimal does not equal NaN", mustDecimal(t, "1d"), math.NaN(), false},
+		{"decimal does not equal infinity", mustDecimal(t, "1d"), math.Inf(1), false},
+		{"decimal does not equal string", mustDecimal(t, "1d"), "1", false},
+
+		{"strings by content", "lox", "lo" + "x", true},
+		{"different strings", "lox", "Lox", false},
+		{"empty strings", "", "", true},
+		{"string does not equal number", "1", 1.0, false},
+
+		{"instance equals itself", instance, instance, true},
+		{"distinct instances with same fields", instance, &object{name: "a"}, false},
+		{"map equals itself", collection, collection, true},
+		{"distinct maps with same entries", collection, map[string]any{"a": 1.0}, false},
+		{"slice equals itself", list, list, true},
+		{"distinct slices with same items", list, []any{1.0, 2.0}, false},
+		{"map does not equal nil", collection, nil, false},
+		{"instance does not equal string", instance, "a", false},
+	}
+
+	for _, c := range cases {
+		if got := isEqual(c.a, c.b); got != c.expected {
+			t.Fatalf("%s: expecting isEqual(%v, %v) to be %v, but got: %v", c.name, c.a, c.b, c.expected, got)
+		}
+		if got := isEqual(c.b, c.a); got != c.expected {
+			t.Fatalf("%s: expecting equality to be symmetric, but isEqual(%v, %v) is %v", c.name, c.b, c.a, got)
+		}
+	}
+}
+
+func TestIsEqualDoesNotPanicOnUncomparableValues(t *testing.T) {
+	type holder struct{ items any }
+
+	a := holder{items: []any{1.0}}
+	b := holder{items: []any{1.0}}
+
+	if isEqual(a, b) {
+		t.Fatal("values without identity should not be equal")
+	}
+}
+
+func TestEqualityOperators(t *testing.T) {
+	cases := map[string]bool{
+		"nil == 5":         false,
+		"5 == nil":         false,
+		"nil == nil":       true,
+		"nil != false":     true,
+		"1 == 1.0d":        true,
+		`"a" == "a"`:       true,
+		`"1" == 1`:         false,
+		"0 == false":       false,
+		"(0 / 0) == 0 / 0": false,
+		"(0 / 0) != 0 / 0": true,
+	}
+
+	for source, expected := range cases {
+		value, err := run(t, "", source)
+		if err.Err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("expecting %q to be %v, but got: %v", source, expected, value)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package visitor
+
+import (
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+)
+
+// Object is a runtime value with properties, read with `value.name`.
+type Object interface {
+	GetProperty(name string) (any, bool)
+}
+
+// LoxError is the error object seen by `catch` for runtime errors and built
+// by `Error(message)` in scripts.
+type LoxError struct {
+	Message string
+	Line    int
+}
+
+func (e *LoxError) GetProperty(name string) (any, bool) {
+	switch name {
+	case "message":
+		return e.Message, true
+	case "line":
+		return float64(e.Line), true
+	}
+
+	return nil, false
+}
+
+func (e *LoxError) String() string {
+	return "Error: " + e.Message
+}
+
+// thrownValue carries the value of a `throw` statement up to the closest
+// enclosing catch clause.
+type thrownValue struct {
+	value any
+}
+
+func (t thrownValue) Error() string {
+	if loxError, ok := t.value.(*LoxError); ok {
+		return loxError.Message
+	}
+
+	return Stringify(t.value)
+}
+
+// isCatchable tells whether a catch clause may handle err. Returns travel
+// as errors too but only function calls may stop them, and limit errors
+// must reach the host.
+func isCatchable(err core.Error) bool {
+	_, isReturn := err.Err.(returnSignal)
+	return !isReturn && !isLimitError(err.Err)
+}
+
+// caughtValue is what a catch clause binds for err: the thrown value itself,
+// or an error object describing a runtime error.
+func (i *Interpreter) caughtValue(err core.Error) (any, core.Error) {
+	if thrown, ok := err.Err.(thrownValue); ok {
+		return thrown.value, core.Error{}
+	}
+
+	message := err.Err.Error()
+	if allocErr := i.budget.reserve(errorObjectSize + len(message)); allocErr != nil {
+		return nil, core.Error{Line: err.Line, Err: allocErr, ExitCode: 70}
+	}
+
+	return &LoxError{Message: message, Line: err.Line}, core.Error{}
+}
@@ -2,18 +2,24 @@ package visitor
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
 )
 
 type Evaluator struct {
-	environment *environment.Environment
+	environment    *environment.Environment
+	decimalContext decimal.Context
+	// interpreter runs the bodies of called functions. Evaluators created
+	// without one can evaluate everything but calls.
+	interpreter *Interpreter
 }
 
 func CreateEvaluator() Evaluator {
-	env := environment.CreateEnvironment()
-	return Evaluator{environment: &env}
+	interpreter := CreateInterpreter()
+	return interpreter.evaluator()
 }
 
 func CreateEvaluatorWithEnvironment(env *environment.Environment) Evaluator {
@@ -21,42 +27,81 @@ func CreateEvaluatorWithEnvironment(env *environment.Environment) Evaluator {
 		panic("nil pointer to enclosing Environment")
 	}
 
-	return Evaluator{environment: env}
+	return Evaluator{environment: env, decimalContext: decimal.DefaultContext}
 }
 
+// SetDecimalContext sets the scale and rounding mode used when dividing decimals.
+func (e *Evaluator) SetDecimalContext(ctx decimal.Context) {
+	e.decimalContext = ctx
+}
+
+// context returns the decimal context to divide with. Evaluators of an
+// interpreter follow its context, which `setRounding` can change while a
+// statement runs.
+func (e Evaluator) context() decimal.Context {
+	if e.interpreter != nil {
+		return e.interpreter.decimalContext
+	}
+	return e.decimalContext
+}
+
+// Evaluate is the single entry point for evaluating an expression and its
+// sub-expressions, so every node counts against the interpreter's limits and
+// is reported to its expression hook.
 func (e Evaluator) Evaluate(expr core.Expression) (any, core.Error) {
-	return expr.Accept(e)
+	if e.interpreter != nil {
+		if err := e.interpreter.budget.step(expressionLine(expr)); err != nil {
+			return nil, *err
+		}
+	}
+
+	value, err := expr.Accept(e)
+	if err.Err == nil && e.interpreter != nil && e.interpreter.expressionHook != nil {
+		e.interpreter.expressionHook.AfterExpression(e.interpreter, expr, core.ExpressionLine(expr), value)
+	}
+
+	return value, err
 }
 
 func (e Evaluator) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
-	rightExpr, err := expr.Right.Accept(e)
+	leftExpr, err := e.Evaluate(expr.Left)
 	if err.Err != nil {
 		return nil, err
 	}
-	leftExpr, err := expr.Left.Accept(e)
+	rightExpr, err := e.Evaluate(expr.Right)
 	if err.Err != nil {
 		return nil, err
 	}
 
-	switch expr.Operator.Type {
+	return e.evaluateBinary(expr.Operator, leftExpr, rightExpr)
+}
+
+// evaluateBinary applies a binary operator to already evaluated operands. It is
+// shared by binary expressions and compound assignments.
+func (e Evaluator) evaluateBinary(operator core.Token, leftExpr any, rightExpr any) (any, core.Error) {
+	if isDecimalOperation(operator, leftExpr, rightExpr) {
+		return e.evaluateDecimalBinary(operator, leftExpr, rightExpr)
+	}
+
+	switch operator.Type {
 	case core.MINUS:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left - right, core.Error{}
 
 	case core.STAR:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left * right, core.Error{}
 
 	case core.SLASH:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left / right, core.Error{}
 
@@ -64,43 +109,76 @@ func (e Evaluator) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
 		leftStr, leftIsString := leftExpr.(string)
 		rightStr, rightIsString := rightExpr.(string)
 		if leftIsString && rightIsString {
+			if err := e.reserve(operator.Line, len(leftStr)+len(rightStr)); err.Err != nil {
+				return nil, err
+			}
 			return fmt.Sprintf("%s%s", leftStr, rightStr), core.Error{}
 		}
 
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be two numbers or two strings."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be two numbers or two strings."), ExitCode: 70}
 		}
 		return left + right, core.Error{}
 
 	case core.GREATER:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left > right, core.Error{}
 
 	case core.GREATER_EQUAL:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left >= right, core.Error{}
 
 	case core.LESS:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left < right, core.Error{}
 
 	case core.LESS_EQUAL:
-		left, right, err := getMultipleFloat(expr.Operator, leftExpr, rightExpr)
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
 		if err != nil {
-			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
 		}
 		return left <= right, core.Error{}
 
+	case core.PERCENT:
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+		}
+		if right == 0 {
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Division by zero."), ExitCode: 70}
+		}
+		return flooredMod(left, right), core.Error{}
+
+	case core.TILDE_SLASH:
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+		}
+		if right == 0 {
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Division by zero."), ExitCode: 70}
+		}
+		return math.Floor(left / right), core.Error{}
+
+	case core.STAR_STAR:
+		left, right, err := getMultipleFloat(operator, leftExpr, rightExpr)
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be numbers."), ExitCode: 70}
+		}
+		return math.Pow(left, right), core.Error{}
+
+	case core.AMPERSAND, core.PIPE, core.CARET, core.LESS_LESS, core.GREATER_GREATER:
+		return evaluateBitwise(operator, leftExpr, rightExpr)
+
 	case core.EQUAL_EQUAL:
 		return isEqual(leftExpr, rightExpr), core.Error{}
 
@@ -112,8 +190,21 @@ func (e Evaluator) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
 	}
 }
 
+// reserve checks that a value of bytes fits in the interpreter's memory
+// quota before it is built.
+func (e Evaluator) reserve(line int, bytes int) core.Error {
+	if e.interpreter == nil {
+		return core.Error{}
+	}
+
+	if err := e.interpreter.budget.reserve(bytes); err != nil {
+		return core.Error{Line: line, Err: err, ExitCode: 70}
+	}
+	return core.Error{}
+}
+
 func (e Evaluator) VisitGroupExpr(expr core.Grouping) (any, core.Error) {
-	value, err := expr.Expr.Accept(e)
+	value, err := e.Evaluate(expr.Expr)
 	if err.Err != nil {
 		return nil, err
 	}
@@ -126,13 +217,17 @@ func (e Evaluator) VisitLiteralExpr(expr core.Literal) (any, core.Error) {
 }
 
 func (e Evaluator) VisitUnaryExpr(expr core.Unary) (any, core.Error) {
-	right, err := expr.Right.Accept(e)
+	right, err := e.Evaluate(expr.Right)
 	if err.Err != nil {
 		return nil, err
 	}
 
 	switch expr.Operator.Type {
 	case core.MINUS:
+		if dec, ok := right.(decimal.Decimal); ok {
+			return dec.Neg(), core.Error{}
+		}
+
 		float, err := getFloat(expr.Operator, right)
 		if err != nil {
 			return nil, core.Error{Line: expr.Operator.Line, Err: err, ExitCode: 70}
@@ -142,6 +237,16 @@ func (e Evaluator) VisitUnaryExpr(expr core.Unary) (any, core.Error) {
 	case core.BANG:
 		return !isTruthy(right), core.Error{}
 
+	case core.TILDE:
+		if _, err := getFloat(expr.Operator, right); err != nil {
+			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operand must be a number."), ExitCode: 70}
+		}
+		integer, err := getInteger(expr.Operator, right)
+		if err != nil {
+			return nil, core.Error{Line: expr.Operator.Line, Err: err, ExitCode: 70}
+		}
+		return float64(^integer), core.Error{}
+
 	default:
 		return nil, core.Error{}
 	}
@@ -157,7 +262,7 @@ func (e Evaluator) VisitVariableExpr(expr core.Variable) (any, core.Error) {
 }
 
 func (e Evaluator) VisitAssignExpr(expr core.Assign) (any, core.Error) {
-	value, err := expr.Value.Accept(e)
+	value, err := e.Evaluate(expr.Value)
 	if err.Err != nil {
 		return nil, err
 	}
@@ -170,28 +275,328 @@ func (e Evaluator) VisitAssignExpr(expr core.Assign) (any, core.Error) {
 	return value, core.Error{}
 }
 
-func isTruthy(value any) bool {
-	if value == nil {
+// VisitCompoundAssignExpr reads the target once, combines it with the value
+// using the arithmetic operator behind `op=` and writes the result back.
+func (e Evaluator) VisitCompoundAssignExpr(expr core.CompoundAssign) (any, core.Error) {
+	current, err := e.environment.GetVariable(&expr.Name)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	value, err := e.Evaluate(expr.Value)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	result, err := e.evaluateBinary(arithmeticOperator(expr.Operator), current, value)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	assignErr := e.environment.AssignVariable(&expr.Name, result)
+	if assignErr != nil {
+		return nil, *assignErr
+	}
+
+	return result, core.Error{}
+}
+
+func (e Evaluator) VisitIncrementExpr(expr core.Increment) (any, core.Error) {
+	current, err := e.environment.GetVariable(&expr.Name)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	if _, isDecimal := current.(decimal.Decimal); !isDecimal {
+		if _, err := getFloat(expr.Operator, current); err != nil {
+			return nil, core.Error{Line: expr.Operator.Line, Err: fmt.Errorf("Operand must be a number."), ExitCode: 70}
+		}
+	}
+
+	result, err := e.evaluateBinary(arithmeticOperator(expr.Operator), current, 1.0)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	assignErr := e.environment.AssignVariable(&expr.Name, result)
+	if assignErr != nil {
+		return nil, *assignErr
+	}
+
+	if expr.Prefix {
+		return result, core.Error{}
+	}
+	return current, core.Error{}
+}
+
+func (e Evaluator) VisitConditionalExpr(expr core.Conditional) (any, core.Error) {
+	condition, err := e.Evaluate(expr.Condition)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	e.branch(expr.Question, isTruthy(condition))
+	if isTruthy(condition) {
+		return e.Evaluate(expr.Then)
+	}
+	return e.Evaluate(expr.Else)
+}
+
+func (e Evaluator) VisitLogicalExpr(expr core.Logical) (any, core.Error) {
+	left, err := e.Evaluate(expr.Left)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	decided := false
+	switch expr.Operator.Type {
+	case core.QUESTION_QUESTION:
+		decided = left != nil
+	case core.OR:
+		decided = isTruthy(left)
+	case core.AND:
+		decided = !isTruthy(left)
+	}
+
+	e.branch(expr.Operator, !decided)
+	if decided {
+		return left, core.Error{}
+	}
+	return e.Evaluate(expr.Right)
+}
+
+// branch reports a decision to the interpreter's branch hook, if any.
+func (e Evaluator) branch(token core.Token, taken bool) {
+	if e.interpreter != nil {
+		e.interpreter.branch(token, taken)
+	}
+}
+
+func (e Evaluator) VisitCallExpr(expr core.Call) (any, core.Error) {
+	callee, err := e.Evaluate(expr.Callee)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	arguments := []any{}
+	for _, argument := range expr.Arguments {
+		value, err := e.Evaluate(argument)
+		if err.Err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, value)
+	}
+
+	function, ok := callee.(Callable)
+	if !ok {
+		return nil, core.Error{Line: expr.Paren.Line, Err: fmt.Errorf("Can only call functions and classes."), ExitCode: 70}
+	}
+
+	arguments, argumentsErr := checkArguments(function, arguments)
+	if argumentsErr != nil {
+		return nil, core.Error{Line: expr.Paren.Line, Err: argumentsErr, ExitCode: 70}
+	}
+
+	if e.interpreter == nil {
+		return nil, core.Error{Line: expr.Paren.Line, Err: fmt.Errorf("Can't call functions here."), ExitCode: 70}
+	}
+
+	return e.interpreter.call(function, arguments, expr.Paren)
+}
+
+func (e Evaluator) VisitGetExpr(expr core.Get) (any, core.Error) {
+	value, err := e.Evaluate(expr.Object)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	object, ok := value.(Object)
+	if !ok {
+		return nil, core.Error{Line: expr.Name.Line, Err: fmt.Errorf("Only objects have properties."), ExitCode: 70}
+	}
+
+	property, ok := object.GetProperty(expr.Name.Lexeme)
+	if !ok {
+		return nil, core.Error{Line: expr.Name.Line, Err: fmt.Errorf("Undefined property '%s'.", expr.Name.Lexeme), ExitCode: 70}
+	}
+
+	return property, core.Error{}
+}
+
+// arithmeticOperator maps `+=`, `++` and friends to the binary operator they
+// apply, keeping the original line for error reporting.
+func arithmeticOperator(operator core.Token) core.Token {
+	switch operator.Type {
+	case core.PLUS_EQUAL, core.PLUS_PLUS:
+		return core.Token{Type: core.PLUS, Lexeme: "+", Line: operator.Line}
+	case core.MINUS_EQUAL, core.MINUS_MINUS:
+		return core.Token{Type: core.MINUS, Lexeme: "-", Line: operator.Line}
+	case core.STAR_EQUAL:
+		return core.Token{Type: core.STAR, Lexeme: "*", Line: operator.Line}
+	case core.SLASH_EQUAL:
+		return core.Token{Type: core.SLASH, Lexeme: "/", Line: operator.Line}
+	}
+
+	return operator
+}
+
+func isDecimalOperation(operator core.Token, left any, right any) bool {
+	if operator.Type == core.EQUAL_EQUAL || operator.Type == core.BANG_EQUAL {
 		return false
 	}
 
-	if boolean, ok := value.(bool); ok {
-		return boolean
+	_, leftIsDecimal := left.(decimal.Decimal)
+	_, rightIsDecimal := right.(decimal.Decimal)
+	return leftIsDecimal || rightIsDecimal
+}
+
+// evaluateDecimalBinary handles arithmetic and comparisons where at least one
+// operand is a decimal. Plain numbers are promoted to decimals so the result
+// never picks up binary floating point artifacts. Decimal results are checked
+// against the memory quota.
+func (e Evaluator) evaluateDecimalBinary(operator core.Token, leftValue any, rightValue any) (any, core.Error) {
+	result, err := e.computeDecimalBinary(operator, leftValue, rightValue)
+	if err.Err != nil {
+		return nil, err
 	}
 
-	return true
+	// Powers were already checked with an estimate before being computed.
+	if dec, ok := result.(decimal.Decimal); ok && operator.Type != core.STAR_STAR {
+		if err := e.reserve(operator.Line, dec.Size()); err.Err != nil {
+			return nil, err
+		}
+	}
+	return result, core.Error{}
 }
 
-func isEqual(a any, b any) bool {
-	if a == nil && b == nil {
-		return true
+func (e Evaluator) computeDecimalBinary(operator core.Token, leftValue any, rightValue any) (any, core.Error) {
+	left, right, err := getMultipleDecimal(operator, leftValue, rightValue)
+	if err != nil && isNumber(leftValue) && isNumber(rightValue) {
+		// infinities and NaN have no decimal value
+		return nil, core.Error{Line: operator.Line, Err: err, ExitCode: 70}
+	}
+	if err != nil {
+		message := "Operands must be numbers."
+		if operator.Type == core.PLUS {
+			message = "Operands must be two numbers or two strings."
+		}
+		return nil, core.Error{Line: operator.Line, Err: fmt.Errorf(message), ExitCode: 70}
 	}
 
-	if a == nil {
-		return true
+	switch operator.Type {
+	case core.MINUS:
+		return left.Sub(right), core.Error{}
+	case core.PLUS:
+		return left.Add(right), core.Error{}
+	case core.STAR:
+		return left.Mul(right), core.Error{}
+	case core.SLASH:
+		quotient, err := left.Div(right, e.context())
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: err, ExitCode: 70}
+		}
+		return quotient, core.Error{}
+	case core.TILDE_SLASH:
+		quotient, err := left.IntegerDiv(right)
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: err, ExitCode: 70}
+		}
+		return quotient, core.Error{}
+	case core.PERCENT:
+		remainder, err := left.Mod(right)
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: err, ExitCode: 70}
+		}
+		return remainder, core.Error{}
+	case core.STAR_STAR:
+		power, ok := right.Int64()
+		if !ok {
+			return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Decimal exponent must be an integer."), ExitCode: 70}
+		}
+		// A power can be far larger than its operands, so it is checked
+		// up front instead of after it is computed.
+		estimate := float64(left.Size()) * math.Abs(float64(power))
+		if estimate > maxDecimalPowerSize {
+			return nil, core.Error{Line: operator.Line, Err: ErrMemoryLimitExceeded, ExitCode: 70}
+		}
+		if err := e.reserve(operator.Line, int(estimate)); err.Err != nil {
+			return nil, err
+		}
+		result, err := left.Pow(power, e.context())
+		if err != nil {
+			return nil, core.Error{Line: operator.Line, Err: err, ExitCode: 70}
+		}
+		return result, core.Error{}
+	case core.AMPERSAND, core.PIPE, core.CARET, core.LESS_LESS, core.GREATER_GREATER:
+		return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Bitwise operands cannot be decimals."), ExitCode: 70}
+	case core.GREATER:
+		return left.Cmp(right) > 0, core.Error{}
+	case core.GREATER_EQUAL:
+		return left.Cmp(right) >= 0, core.Error{}
+	case core.LESS:
+		return left.Cmp(right) < 0, core.Error{}
+	case core.LESS_EQUAL:
+		return left.Cmp(right) <= 0, core.Error{}
+	default:
+		return nil, core.Error{}
+	}
+}
+
+// maxDecimalPowerSize bounds the estimated size of a decimal power, with or
+// without a memory limit. Powers much larger take minutes to compute.
+const maxDecimalPowerSize = 1 << 20
+
+// evaluateBitwise applies a bitwise or shift operator to two numbers holding
+// whole values, working on their int64 representation.
+func evaluateBitwise(operator core.Token, leftValue any, rightValue any) (any, core.Error) {
+	left, err := getInteger(operator, leftValue)
+	if err != nil {
+		return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be integers."), ExitCode: 70}
+	}
+	right, err := getInteger(operator, rightValue)
+	if err != nil {
+		return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Operands must be integers."), ExitCode: 70}
+	}
+
+	switch operator.Type {
+	case core.AMPERSAND:
+		return float64(left & right), core.Error{}
+	case core.PIPE:
+		return float64(left | right), core.Error{}
+	case core.CARET:
+		return float64(left ^ right), core.Error{}
 	}
 
-	return a == b
+	if right < 0 {
+		return nil, core.Error{Line: operator.Line, Err: fmt.Errorf("Shift count must not be negative."), ExitCode: 70}
+	}
+
+	if operator.Type == core.LESS_LESS {
+		return float64(left << right), core.Error{}
+	}
+	return float64(left >> right), core.Error{}
+}
+
+// flooredMod returns the remainder with the sign of the divisor, pairing with
+// `~/` rounding towards negative infinity.
+func flooredMod(left float64, right float64) float64 {
+	remainder := math.Mod(left, right)
+	if remainder != 0 && (remainder < 0) != (right < 0) {
+		remainder += right
+	}
+	return remainder
+}
+
+func isTruthy(value any) bool {
+	if value == nil {
+		return false
+	}
+
+	if boolean, ok := value.(bool); ok {
+		return boolean
+	}
+
+	return true
 }
 
 func getFloat(operator core.Token, operand any) (float64, error) {
@@ -217,6 +622,53 @@ func getFloat(operator core.Token, operand any) (float64, error) {
 	return 0, fmt.Errorf("%v Operand must be a number.", operator)
 }
 
+func getDecimal(operator core.Token, operand any) (decimal.Decimal, error) {
+	if dec, ok := operand.(decimal.Decimal); ok {
+		return dec, nil
+	}
+
+	float, err := getFloat(operator, operand)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return decimal.FromFloat(float)
+}
+
+func isNumber(value any) bool {
+	switch value.(type) {
+	case float64, decimal.Decimal:
+		return true
+	}
+	return false
+}
+
+func getMultipleDecimal(operator core.Token, a any, b any) (decimal.Decimal, decimal.Decimal, error) {
+	aDecimal, err := getDecimal(operator, a)
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, err
+	}
+	bDecimal, err := getDecimal(operator, b)
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, err
+	}
+
+	return aDecimal, bDecimal, nil
+}
+
+func getInteger(operator core.Token, operand any) (int64, error) {
+	float, err := getFloat(operator, operand)
+	if err != nil {
+		return 0, err
+	}
+
+	if float != math.Trunc(float) || float < math.MinInt64 || float >= math.MaxInt64 {
+		return 0, fmt.Errorf("Operand must be an integer.")
+	}
+
+	return int64(float), nil
+}
+
 func getMultipleFloat(operator core.Token, a any, b any) (float64, float64, error) {
 	aFloat, err := getFloat(operator, a)
 	if err != nil {
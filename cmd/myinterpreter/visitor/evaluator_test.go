@@ -0,0 +1,483 @@
+package visitor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+func parseExpression(t *testing.T, source string) core.Expression {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(source))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors for %q, but got: %v", source, errs[0].Err)
+	}
+
+	expressions, err := parser.ParseExpressions(tokens)
+	if err != nil {
+		t.Fatalf("was not expecting any parse errors for %q, but got: %v", source, err.Err)
+	}
+	if len(expressions) != 1 {
+		t.Fatalf("there should be 1 expression in %q, but got: %d", source, len(expressions))
+	}
+
+	return expressions[0]
+}
+
+// run interprets program and then evaluates expression in the resulting
+// global scope, returning the value and any runtime error.
+func run(t *testing.T, program string, expression string) (any, core.Error) {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(program))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors, but got: %v", errs[0].Err)
+	}
+
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any parse errors, but got: %v", parseErr.Err)
+	}
+
+	interpreter := CreateInterpreter()
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			return nil, err
+		}
+	}
+
+	evaluator := interpreter.evaluator()
+	return evaluator.Evaluate(parseExpression(t, expression))
+}
+
+func TestBinaryOperatorsGroupLeftToRight(t *testing.T) {
+	cases := map[string]string{
+		"1 == 2 == 3":       "(== (== 1.0 2.0) 3.0)",
+		"1 != 2 == 3":       "(== (!= 1.0 2.0) 3.0)",
+		"1 < 2 < 3":         "(< (< 1.0 2.0) 3.0)",
+		"1 - 2 - 3":         "(- (- 1.0 2.0) 3.0)",
+		"1 - 2 + 3":         "(+ (- 1.0 2.0) 3.0)",
+		"8 / 4 / 2":         "(/ (/ 8.0 4.0) 2.0)",
+		"8 % 5 * 2":         "(* (% 8.0 5.0) 2.0)",
+		"8 ~/ 3 / 2":        "(/ (~/ 8.0 3.0) 2.0)",
+		"1 << 2 >> 3":       "(>> (<< 1.0 2.0) 3.0)",
+		"1 & 2 & 3":         "(& (& 1.0 2.0) 3.0)",
+		"1 ^ 2 ^ 3":         "(^ (^ 1.0 2.0) 3.0)",
+		"1 | 2 | 3":         "(| (| 1.0 2.0) 3.0)",
+		"a ?? b ?? c":       "(?? (?? a b) c)",
+		"2 ** 3 ** 2":       "(** 2.0 (** 3.0 2.0))",
+		"a ? b : c ? d : e": "(?: a b (?: c d e))",
+	}
+
+	for source, expected := range cases {
+		str, err := parseExpression(t, source).Accept(StringifyVisitor{})
+		if err.Err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if str != expected {
+			t.Fatalf("expecting %q to parse as %s, but got: %s", source, expected, str)
+		}
+	}
+}
+
+func TestBinaryOperatorPrecedence(t *testing.T) {
+	cases := map[string]string{
+		"1 + 2 * 3":          "(+ 1.0 (* 2.0 3.0))",
+		"1 + 2 == 3":         "(== (+ 1.0 2.0) 3.0)",
+		"1 < 2 == 2 > 1":     "(== (< 1.0 2.0) (> 2.0 1.0))",
+		"-2 ** 2":            "(- (** 2.0 2.0))",
+		"2 ** -1":            "(** 2.0 (- 1.0))",
+		"1 | 2 ^ 3 & 4 << 5": "(| 1.0 (^ 2.0 (& 3.0 (<< 4.0 5.0))))",
+		"1 << 2 + 3":         "(<< 1.0 (+ 2.0 3.0))",
+		"1 | 2 < 3":          "(< (| 1.0 2.0) 3.0)",
+		"a ?? b == c":        "(?? a (== b c))",
+		"a ?? b ? c : d":     "(?: (?? a b) c d)",
+		"!a == b":            "(== (! a) b)",
+	}
+
+	for source, expected := range cases {
+		str, err := parseExpression(t, source).Accept(StringifyVisitor{})
+		if err.Err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if str != expected {
+			t.Fatalf("expecting %q to parse as %s, but got: %s", source, expected, str)
+		}
+	}
+}
+
+func TestBinaryOperatorsEvaluateLeftAssociative(t *testing.T) {
+	cases := map[string]any{
+		"1 - 2 - 3":      -4.0,
+		"8 / 4 / 2":      1.0,
+		"2 - 3 + 4":      3.0,
+		"10 % 4 % 3":     2.0,
+		"1 == 1 == true": true,
+		"1 != 2 != true": false,
+		"2 ** 3 ** 2":    512.0,
+		"-2 ** 2":        -4.0,
+	}
+
+	for source, expected := range cases {
+		value, err := run(t, "", source)
+		if err.Err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("expecting %q to evaluate to %v, but got: %v", source, expected, value)
+		}
+	}
+}
+
+func TestBinaryOperandsEvaluateLeftToRight(t *testing.T) {
+	value, err := run(t, "var i = 0;", "(i++) - (i++)")
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if value != -1.0 {
+		t.Fatalf("expecting the left operand to be evaluated first (-1), but got: %v", value)
+	}
+
+	value, err = run(t, `var s = "";`, `(s += "a") + (s += "b")`)
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if value != "aab" {
+		t.Fatalf("expecting the left operand to be evaluated first (aab), but got: %v", value)
+	}
+}
+
+func TestBinaryErrorReportsLeftOperandFirst(t *testing.T) {
+	_, err := run(t, "", "left + right")
+	if err.Err == nil {
+		t.Fatal("was expecting an undefined variable error, but didn't get one")
+	}
+	if !strings.Contains(err.Err.Error(), "'left'") {
+		t.Fatalf("expecting the error to point at the left operand, but got: %v", err.Err)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	cases := map[string]string{
+		"0.1d + 0.2d":    "0.3",
+		"19.99d - 0.99d": "19.00",
+		"1.10d * 3d":     "3.30",
+		"-2.5d * 0.2d":   "-0.50",
+		"10d / 4d":       "2.5",
+		"1d / 3d":        "0.3333333333333333",
+		"0.1d + 0.2":     "0.3",
+		"0.1 + 0.2d":     "0.3",
+		"2 * 1.05d":      "2.10",
+		"1 / 8d":         "0.125",
+		"7d ~/ 2d":       "3",
+		"7.5d % 2":       "1.5",
+	}
+
+	for source, expected := range cases {
+		value, err := run(t, "", source)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", source, err.Err)
+		}
+		if _, isDecimal := value.(decimal.Decimal); !isDecimal {
+			t.Fatalf("%s: expecting a decimal, but got: %T", source, value)
+		}
+		if got := Stringify(value); got != expected {
+			t.Fatalf("%s: expecting %s, but got: %s", source, expected, got)
+		}
+	}
+}
+
+func TestDecimalComparisons(t *testing.T) {
+	cases := map[string]bool{
+		"0.1d + 0.2d == 0.3d": true,
+		"1.10d == 1.1d":       true,
+		"0.3d > 0.29d":        true,
+		"0.3d >= 0.30d":       true,
+		"-1d < 0d":            true,
+		"2d <= 1.99d":         false,
+		"0.5d == 0.5":         true,
+		"0.1d < 0.2":          true,
+		"3 > 2.99d":           true,
+		"1d != 1":             false,
+	}
+
+	for source, expected := range cases {
+		value, err := run(t, "", source)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", source, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", source, expected, value)
+		}
+	}
+}
+
+func TestDecimalErrors(t *testing.T) {
+	cases := map[string]string{
+		"1d / 0d":    "Division by zero.",
+		"1d / 0":     "Division by zero.",
+		`1d + "a"`:   "Operands must be two numbers or two strings.",
+		"1d < nil":   "Operands must be numbers.",
+		"1d & 1":     "Bitwise operands cannot be decimals.",
+		"2d ** 0.5d": "Decimal exponent must be an integer.",
+		"1d + 1 / 0": "Cannot convert INF to a decimal.",
+	}
+
+	for source, expected := range cases {
+		_, err := run(t, "", source)
+		if err.Err == nil || err.Err.Error() != expected {
+			t.Fatalf("%s: expecting error %q, but got: %v", source, expected, err.Err)
+		}
+	}
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	cases := map[string]float64{
+		"7 % 3":       1,
+		"-7 % 3":      2,
+		"7 % -3":      -2,
+		"7.5 % 2":     1.5,
+		"7 ~/ 2":      3,
+		"-7 ~/ 2":     -4,
+		"7.5 ~/ 2":    3,
+		"2 ** 10":     1024,
+		"2 ** -1":     0.5,
+		"2 ** 3 ** 2": 512,
+		"-2 ** 2":     -4,
+		"(-2) ** 2":   4,
+		"6 & 3":       2,
+		"6 | 3":       7,
+		"6 ^ 3":       5,
+		"~5":          -6,
+		"1 << 4":      16,
+		"-16 >> 2":    -4,
+		"1 | 2 ^ 3":   1,
+	}
+
+	for source, expected := range cases {
+		value, err := run(t, "", source)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", source, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", source, expected, value)
+		}
+	}
+}
+
+func TestOperatorsRejectNonNumericOperands(t *testing.T) {
+	cases := map[string]string{
+		`"a" % 2`:  "Operands must be numbers.",
+		`2 ~/ "a"`: "Operands must be numbers.",
+		"nil ** 2": "Operands must be numbers.",
+		"true & 1": "Operands must be integers.",
+		`1 | "a"`:  "Operands must be integers.",
+		"1.5 ^ 1":  "Operands must be integers.",
+		"1 << 0.5": "Operands must be integers.",
+		`~"a"`:     "Operand must be a number.",
+		"~1.5":     "Operand must be an integer.",
+		"1 >> -1":  "Shift count must not be negative.",
+		"1 % 0":    "Division by zero.",
+		"1 ~/ 0":   "Division by zero.",
+	}
+
+	for source, expected := range cases {
+		_, err := run(t, "", source)
+		if err.Err == nil || err.Err.Error() != expected || err.ExitCode != 70 {
+			t.Fatalf("%s: expecting runtime error %q, but got: %v", source, expected, err.Err)
+		}
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	cases := map[string]any{
+		"var x = 5; x += 2;":       7.0,
+		"var x = 5; x -= 2;":       3.0,
+		"var x = 5; x *= 2;":       10.0,
+		"var x = 5; x /= 2;":       2.5,
+		`var x = "a"; x += "b";`:   "ab",
+		"var x = 0.1d; x += 0.2d;": "0.3",
+	}
+
+	for program, expected := range cases {
+		value, err := run(t, program, "x")
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", program, err.Err)
+		}
+		if _, isDecimal := value.(decimal.Decimal); isDecimal {
+			value = Stringify(value)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", program, expected, value)
+		}
+	}
+}
+
+func TestCompoundAssignmentReadsTargetOnceBeforeValue(t *testing.T) {
+	// the value assigns the target too: reading the target again after it
+	// would give a different result
+	cases := map[string]float64{
+		"var x = 1; var r = x += (x = 10);": 11,
+		"var x = 6; var r = x -= (x = 1);":  5,
+		"var x = 2; var r = x *= (x = 3);":  6,
+		"var x = 8; var r = x /= (x = 2);":  4,
+	}
+
+	for program, expected := range cases {
+		for _, name := range []string{"r", "x"} {
+			value, err := run(t, program, name)
+			if err.Err != nil {
+				t.Fatalf("%s: was not expecting any errors, but got: %v", program, err.Err)
+			}
+			if value != expected {
+				t.Fatalf("%s: expecting %s to be %v, but got: %v", program, name, expected, value)
+			}
+		}
+	}
+}
+
+func TestIncrementAndDecrement(t *testing.T) {
+	program := "var i = 5; var a = i++; var b = ++i; var c = i--; var d = --i;"
+	cases := map[string]any{"a": 5.0, "b": 7.0, "c": 7.0, "d": 5.0, "i": 5.0}
+	for expression, expected := range cases {
+		value, err := run(t, program, expression)
+		if err.Err != nil {
+			t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+		}
+		if value != expected {
+			t.Fatalf("expecting %s to be %v, but got: %v", expression, expected, value)
+		}
+	}
+
+	value, _ := run(t, "var m = 1.5d; m++;", "m")
+	if Stringify(value) != "2.5" {
+		t.Fatalf("expecting a decimal to increment, but got: %v", value)
+	}
+}
+
+// expressionCounter counts the expressions evaluated, by type.
+type expressionCounter map[string]int
+
+func (c expressionCounter) BeforeStatement(interpreter *Interpreter, stmt core.Statement, line int) error {
+	return nil
+}
+
+func (c expressionCounter) AfterExpression(interpreter *Interpreter, expr core.Expression, line int, value any) {
+	c[fmt.Sprintf("%T", expr)]++
+}
+
+func TestCompoundTargetsAreEvaluatedOnce(t *testing.T) {
+	interpreter := CreateInterpreter()
+	if err := interpretSource(t, &interpreter, "var x = 1;"); err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+
+	counter := expressionCounter{}
+	interpreter.SetHook(counter)
+	if err := interpretSource(t, &interpreter, "x += 1; x -= 1; x *= 2; x /= 2; x++; ++x; x--; --x;"); err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+
+	// the target is read once by the assignment itself, never as a
+	// separate variable expression
+	if counter["core.Variable"] != 0 || counter["core.CompoundAssign"] != 4 || counter["core.Increment"] != 4 {
+		t.Fatalf("expecting 4 compound assignments, 4 increments and no variable reads, but got: %v", counter)
+	}
+
+	value, _ := interpreter.Global("x")
+	if value != 1.0 {
+		t.Fatalf("expecting x to be back to 1, but got: %v", value)
+	}
+}
+
+func TestCompoundAssignmentErrors(t *testing.T) {
+	cases := map[string]string{
+		`var s = "a"; s++;`:    "Operand must be a number.",
+		"var n; --n;":          "Operand must be a number.",
+		"var n; n += 1;":       "Operands must be two numbers or two strings.",
+		`var s = "a"; s *= 2;`: "Operands must be numbers.",
+		"y += 1;":              "Undefined variable 'y'.",
+		"y++;":                 "Undefined variable 'y'.",
+	}
+
+	for program, expected := range cases {
+		_, err := run(t, program, "nil")
+		if err.Err == nil || err.Err.Error() != expected || err.ExitCode != 70 {
+			t.Fatalf("%s: expecting runtime error %q, but got: %v", program, expected, err.Err)
+		}
+	}
+}
+
+func TestConditionalAndCoalesceValues(t *testing.T) {
+	cases := map[string]any{
+		"true ? 1 : 2":            1.0,
+		"nil ? 1 : 2":             2.0,
+		"0 ? 1 : 2":               1.0,
+		`"" ? 1 : 2`:              1.0,
+		"false ? 1 : nil ? 2 : 3": 3.0,
+		"nil ?? 1":                1.0,
+		"false ?? 1":              false,
+		"0 ?? 1":                  0.0,
+		`"" ?? 1`:                 "",
+		"nil ?? nil ?? 3":         3.0,
+		"nil ?? false ?? 3":       false,
+		"nil ?? nil":              nil,
+	}
+
+	for source, expected := range cases {
+		value, err := run(t, "", source)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", source, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", source, expected, value)
+		}
+	}
+}
+
+func TestConditionalAndCoalesceShortCircuit(t *testing.T) {
+	cases := map[string]string{
+		`(log += "c") ? (log += "t") : (log += "e")`: "ct",
+		`nil ? (log += "t") : (log += "e")`:          "e",
+		`(log += "l") ?? (log += "r")`:               "l",
+		`false ?? (log += "r")`:                      "",
+		`nil ?? (log += "r")`:                        "r",
+		`nil ?? nil ?? (log += "c")`:                 "c",
+	}
+
+	for expression, expected := range cases {
+		value, err := run(t, `var log = ""; var result = `+expression+";", "log")
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", expression, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting the operands run to be %q, but got: %v", expression, expected, value)
+		}
+	}
+}
+
+func TestStringifyVariablesAndAssignments(t *testing.T) {
+	cases := map[string]string{
+		"a":             "a",
+		"a = b = 1":     "(= a (= b 1.0))",
+		"a ?? b":        "(?? a b)",
+		"a ? b : c":     "(?: a b c)",
+		"a = b ? c : d": "(= a (?: b c d))",
+	}
+
+	for source, expected := range cases {
+		str, err := parseExpression(t, source).Accept(StringifyVisitor{})
+		if err.Err != nil {
+			t.Fatalf("was not expecting any errors for %q, but got: %v", source, err.Err)
+		}
+		if str != expected {
+			t.Fatalf("expecting %q to print as %s, but got: %s", source, expected, str)
+		}
+	}
+}
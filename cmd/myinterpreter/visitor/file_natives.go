@@ -0,0 +1,276 @@
+package visitor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrFileAccessDenied is raised by the file natives and imports when the
+// interpreter's FileAccess doesn't allow the file. Scripts can catch it like any other
+// runtime error.
+var ErrFileAccessDenied = errors.New("File access denied.")
+
+// FileAccess decides which files scripts may use through the file natives
+// and import. The zero value allows every file the process can reach.
+type FileAccess struct {
+	// Disabled makes every file native and import fail.
+	Disabled bool
+	// Root, when set, confines scripts to the files under that directory.
+	// Relative paths are then resolved against it rather than the working
+	// directory, and symbolic links can't lead out of it.
+	Root string
+}
+
+// SetFileAccess restricts the files scripts may read, write and import.
+func (i *Interpreter) SetFileAccess(access FileAccess) {
+	i.fileAccess = access
+}
+
+// resolve returns the path a script may use for name, or an error wrapping
+// ErrFileAccessDenied.
+func (a FileAccess) resolve(name string) (string, error) {
+	if a.Disabled {
+		return "", ErrFileAccessDenied
+	}
+	if a.Root == "" {
+		return name, nil
+	}
+
+	root, err := filepath.Abs(a.Root)
+	if err != nil {
+		return "", err
+	}
+	root = realPath(root)
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+
+	relative, err := filepath.Rel(root, realPath(path))
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w Path '%s' is outside of '%s'.", ErrFileAccessDenied, name, a.Root)
+	}
+	return path, nil
+}
+
+// realPath follows the symbolic links of the part of path that exists.
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(realPath(parent), filepath.Base(path))
+}
+
+func fileNatives() []*NativeFunction {
+	return []*NativeFunction{
+		{name: "readFile", arity: 1, function: readFile},
+		{name: "writeFile", arity: 2, function: writeFile},
+		{name: "appendFile", arity: 2, function: appendFile},
+		{name: "listDir", arity: 1, function: listDir},
+		{name: "exists", arity: 1, function: exists},
+		{name: "readLines", arity: 1, function: readLines},
+	}
+}
+
+// fileError turns errors from the os package into messages naming the path
+// the script gave.
+func fileError(action string, name string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("Could not %s '%s': %v.", action, name, err)
+}
+
+// filePath checks the path argument of a native and resolves it.
+func filePath(interpreter *Interpreter, native string, argument any) (string, string, error) {
+	name, ok := argument.(string)
+	if !ok {
+		return "", "", fmt.Errorf("%s expects a path string.", native)
+	}
+
+	path, err := interpreter.fileAccess.resolve(name)
+	return name, path, err
+}
+
+func readFile(interpreter *Interpreter, arguments []any) (any, error) {
+	name, path, err := filePath(interpreter, "readFile", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fileError("read", name, err)
+	}
+	if err := interpreter.budget.reserve(int(info.Size())); err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fileError("read", name, err)
+	}
+	return string(content), nil
+}
+
+func writeFile(interpreter *Interpreter, arguments []any) (any, error) {
+	return nil, write(interpreter, "writeFile", arguments, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+func appendFile(interpreter *Interpreter, arguments []any) (any, error) {
+	return nil, write(interpreter, "appendFile", arguments, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+}
+
+func write(interpreter *Interpreter, native string, arguments []any, flag int) error {
+	name, path, err := filePath(interpreter, native, arguments[0])
+	if err != nil {
+		return err
+	}
+	content, ok := arguments[1].(string)
+	if !ok {
+		return fmt.Errorf("%s expects a path and a string.", native)
+	}
+
+	file, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return fileError("write", name, err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return fileError("write", name, err)
+	}
+	if err := file.Close(); err != nil {
+		return fileError("write", name, err)
+	}
+	return nil
+}
+
+// listDir returns the names of the entries of a directory, sorted.
+func listDir(interpreter *Interpreter, arguments []any) (any, error) {
+	name, path, err := filePath(interpreter, "listDir", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fileError("list", name, err)
+	}
+
+	names := make([]string, len(entries))
+	size := 0
+	for index, entry := range entries {
+		names[index] = entry.Name()
+		size += len(entry.Name()) + listEntrySize
+	}
+	if err := interpreter.budget.reserve(size); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	elements := make([]any, len(names))
+	for index, entry := range names {
+		elements[index] = entry
+	}
+	return &List{Elements: elements}, nil
+}
+
+// exists tells whether a file or directory exists. A path outside of the
+// allowed root is still an error, so scripts can't probe for files there.
+func exists(interpreter *Interpreter, arguments []any) (any, error) {
+	name, path, err := filePath(interpreter, "exists", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return nil, fileError("check", name, err)
+	}
+	return true, nil
+}
+
+// readLines opens a file for reading one line at a time.
+func readLines(interpreter *Interpreter, arguments []any) (any, error) {
+	name, path, err := filePath(interpreter, "readLines", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fileError("read", name, err)
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxAllocation)
+	return &LineReader{name: name, file: file, scanner: scanner}, nil
+}
+
+// LineReader is the value of `readLines(path)`. `next()` returns the next
+// line without its line ending, or nil once the file is exhausted, at which
+// point the file is closed. `close()` closes it earlier.
+type LineReader struct {
+	name    string
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+func (r *LineReader) GetProperty(name string) (any, bool) {
+	switch name {
+	case "next":
+		return &NativeFunction{name: "next", arity: 0, function: r.next}, true
+	case "close":
+		return &NativeFunction{name: "close", arity: 0, function: func(interpreter *Interpreter, arguments []any) (any, error) {
+			r.close()
+			return nil, nil
+		}}, true
+	}
+
+	return nil, false
+}
+
+func (r *LineReader) next(interpreter *Interpreter, arguments []any) (any, error) {
+	if r.file == nil {
+		return nil, nil
+	}
+
+	if !r.scanner.Scan() {
+		err := r.scanner.Err()
+		r.close()
+		if err != nil {
+			return nil, fileError("read", r.name, err)
+		}
+		return nil, nil
+	}
+
+	line := strings.TrimSuffix(r.scanner.Text(), "\r")
+	return newString(interpreter, line)
+}
+
+func (r *LineReader) close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+func (r *LineReader) String() string {
+	return fmt.Sprintf("<lines %s>", r.name)
+}
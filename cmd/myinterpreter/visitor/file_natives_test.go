@@ -0,0 +1,113 @@
+package visitor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+// runWithFiles runs program with the given file access and returns the
+// value of the global `result`.
+func runWithFiles(t *testing.T, access FileAccess, program string) (any, core.Error) {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(program))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors, but got: %v", errs[0].Err)
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any parse errors, but got: %v", parseErr.Err)
+	}
+
+	interpreter := CreateInterpreter()
+	interpreter.SetFileAccess(access)
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			return nil, err
+		}
+	}
+	result, _ := interpreter.Global("result")
+	return result, core.Error{}
+}
+
+func TestFileNatives(t *testing.T) {
+	dir := t.TempDir()
+	// Lox strings have no escapes, so line endings are put in beforehand.
+	program := strings.NewReplacer("DIR", dir, `\n`, "\n", `\r`, "\r").Replace(`
+writeFile("DIR/report.txt", "one\n");
+appendFile("DIR/report.txt", "two\r\nthree");
+var lines = readLines("DIR/report.txt");
+var result = "";
+var line;
+while ((line = lines.next()) != nil) result = result + "[" + line + "]";
+result = result + join(listDir("DIR"), ",") + " " + readFile("DIR/report.txt");
+if (exists("DIR/missing.txt")) result = "wrong";
+`)
+
+	result, err := runWithFiles(t, FileAccess{}, program)
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+
+	expected := "[one][two][three]report.txt one\ntwo\r\nthree"
+	if result != expected {
+		t.Fatalf("expecting %q, but got: %q", expected, result)
+	}
+}
+
+func TestFileAccessCanBeDisabled(t *testing.T) {
+	_, err := runWithFiles(t, FileAccess{Disabled: true}, "\nexists(\".\");")
+	if !errors.Is(err, ErrFileAccessDenied) || err.Line != 2 {
+		t.Fatalf("expecting access to be denied on line 2, but got: %v on line %d", err.Err, err.Line)
+	}
+}
+
+func TestFileAccessIsConfinedToRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	access := FileAccess{Root: root}
+	result, err := runWithFiles(t, access, `writeFile("notes.txt", "hi"); var result = readFile("notes.txt");`)
+	if err.Err != nil || result != "hi" {
+		t.Fatalf("expecting relative paths to resolve under the root, but got: %v (%v)", result, err.Err)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "notes.txt")); statErr != nil {
+		t.Fatalf("expecting notes.txt to be written under the root, but got: %v", statErr)
+	}
+
+	for _, path := range []string{"../secret.txt", filepath.Join(dir, "secret.txt"), "link.txt", "sub/../../secret.txt"} {
+		_, err := runWithFiles(t, access, `readFile("`+path+`");`)
+		if !errors.Is(err, ErrFileAccessDenied) {
+			t.Fatalf("%s: expecting access to be denied, but got: %v", path, err.Err)
+		}
+	}
+}
+
+func TestFileErrorsAreCatchable(t *testing.T) {
+	result, err := runWithFiles(t, FileAccess{Root: t.TempDir()}, `
+var result;
+try { readFile("missing.txt"); } catch (e) { result = e.message; }
+`)
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if result != "Could not read 'missing.txt': no such file or directory." {
+		t.Fatalf("expecting a read error, but got: %v", result)
+	}
+}
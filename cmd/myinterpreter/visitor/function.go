@@ -0,0 +1,64 @@
+package visitor
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
+)
+
+// Callable is any runtime value that can appear before `(...)`.
+type Callable interface {
+	Name() string
+	Arity() int
+	Call(interpreter *Interpreter, arguments []any) (any, core.Error)
+}
+
+type LoxFunction struct {
+	declaration core.FunctionStmt
+	closure     environment.Environment
+	// script is the file declaring the function, for stack traces.
+	script string
+}
+
+func (f *LoxFunction) Name() string {
+	return f.declaration.Name.Lexeme
+}
+
+func (f *LoxFunction) Arity() int {
+	return len(f.declaration.Params)
+}
+
+func (f *LoxFunction) Call(interpreter *Interpreter, arguments []any) (any, core.Error) {
+	env := environment.CreateEnvironmentWithEnclosing(&f.closure)
+	defer env.Release()
+	for index, param := range f.declaration.Params {
+		if err := env.AddVariable(param.Lexeme, arguments[index]); err != nil {
+			return nil, core.Error{Line: param.Line, Err: err, ExitCode: 70}
+		}
+	}
+
+	_, err := interpreter.executeBlock(f.declaration.Body, env)
+	if signal, ok := err.Err.(returnSignal); ok {
+		return signal.value, core.Error{}
+	}
+	if err.Err != nil {
+		return nil, err
+	}
+
+	return nil, core.Error{}
+}
+
+func (f *LoxFunction) String() string {
+	return fmt.Sprintf("<fn %s>", f.Name())
+}
+
+// returnSignal unwinds the statements of a function body up to the call that
+// is running it, carrying the returned value.
+type returnSignal struct {
+	value any
+}
+
+func (r returnSignal) Error() string {
+	return "Can't return from top-level code."
+}
@@ -0,0 +1,118 @@
+package visitor
+
+import (
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
+)
+
+// Hook is told about every statement before the interpreter runs it, so tools
+// such as the debugger can follow a script and pause it. Returning an error
+// stops the script with that error.
+type Hook interface {
+	BeforeStatement(interpreter *Interpreter, stmt core.Statement, line int) error
+}
+
+// ExpressionHook is implemented by hooks that also want the value of every
+// expression evaluated, sub-expressions included. It is called after the
+// expression, and not at all when evaluating it fails.
+type ExpressionHook interface {
+	AfterExpression(interpreter *Interpreter, expr core.Expression, line int, value any)
+}
+
+// BranchHook is implemented by hooks that also want to know which way every
+// decision went. Decisions are identified by their token: the keyword of an
+// if, while or for statement, the `?` of a conditional expression or the
+// operator of a logical one. taken tells whether the first way was chosen:
+// the then branch, the loop body, or evaluating the right operand.
+type BranchHook interface {
+	Branch(interpreter *Interpreter, token core.Token, taken bool)
+}
+
+// SetHook installs a hook, or removes it when hook is nil.
+func (i *Interpreter) SetHook(hook Hook) {
+	i.hook = hook
+	i.expressionHook, _ = hook.(ExpressionHook)
+	i.branchHook, _ = hook.(BranchHook)
+}
+
+// branch reports a decision to the branch hook.
+func (i *Interpreter) branch(token core.Token, taken bool) {
+	if i.branchHook != nil {
+		i.branchHook.Branch(i, token, taken)
+	}
+}
+
+// ScopeDepth returns how deeply the current scope is nested in the globals.
+func (i *Interpreter) ScopeDepth() int {
+	depth := 0
+	for env := i.environment.Enclosing(); env != nil; env = env.Enclosing() {
+		depth++
+	}
+	return depth
+}
+
+// Frame is a function being executed: its name, the file and line it has
+// reached and the innermost scope it is running in.
+type Frame struct {
+	Function    string
+	Script      string
+	Line        int
+	Environment *environment.Environment
+}
+
+// CallDepth returns the number of function calls being executed.
+func (i *Interpreter) CallDepth() int {
+	return len(i.frames)
+}
+
+// Frames lists the frames from the innermost one, which has reached line,
+// out to the script itself. Their environments are only valid until the
+// interpreter carries on.
+func (i *Interpreter) Frames(line int) []Frame {
+	frames := []Frame{}
+	current := &i.environment
+	for index := len(i.frames) - 1; index >= 0; index-- {
+		frame := &i.frames[index]
+		frames = append(frames, Frame{Function: frame.function, Script: frame.script, Line: line, Environment: current})
+		line = frame.callLine
+		current = &frame.caller
+	}
+
+	return append(frames, Frame{Function: scriptFrameName, Script: i.scriptName, Line: line, Environment: current})
+}
+
+// CombineHooks returns a hook calling each of hooks in turn. The first error
+// stops the statement from running.
+func CombineHooks(hooks ...Hook) Hook {
+	if len(hooks) == 1 {
+		return hooks[0]
+	}
+	return hookList(hooks)
+}
+
+type hookList []Hook
+
+func (hooks hookList) BeforeStatement(interpreter *Interpreter, stmt core.Statement, line int) error {
+	for _, hook := range hooks {
+		if err := hook.BeforeStatement(interpreter, stmt, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (hooks hookList) Branch(interpreter *Interpreter, token core.Token, taken bool) {
+	for _, hook := range hooks {
+		if branchHook, ok := hook.(BranchHook); ok {
+			branchHook.Branch(interpreter, token, taken)
+		}
+	}
+}
+
+func (hooks hookList) AfterExpression(interpreter *Interpreter, expr core.Expression, line int, value any) {
+	for _, hook := range hooks {
+		if expressionHook, ok := hook.(ExpressionHook); ok {
+			expressionHook.AfterExpression(interpreter, expr, line, value)
+		}
+	}
+}
@@ -1,67 +1,237 @@
 package visitor
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"io"
+	"math"
+	"math/rand"
+	"os"
 
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
 	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
 )
 
+const scriptFrameName = "<script>"
+
 type Interpreter struct {
-	environment environment.Environment
+	environment    environment.Environment
+	decimalContext decimal.Context
+	scriptName     string
+	frames         []callFrame
+	budget         *budget
+	modules        *modules
+	random         *rand.Rand
+	fileAccess     FileAccess
+	hook           Hook
+	expressionHook ExpressionHook
+	branchHook     BranchHook
+	output         io.Writer
+}
+
+// callFrame records a function being executed, the file it was declared
+// in, the line of the call that entered it and the environment the caller
+// was running in.
+type callFrame struct {
+	function string
+	script   string
+	callLine int
+	caller   environment.Environment
 }
 
 func CreateInterpreter() Interpreter {
-	return Interpreter{environment: environment.CreateEnvironment()}
+	budget := &budget{limits: DefaultLimits}
+	globals := environment.CreateEnvironment()
+	defineNatives(&globals)
+	globals.SetAllocator(budget)
+
+	return Interpreter{
+		environment:    globals,
+		decimalContext: decimal.DefaultContext,
+		scriptName:     "<input>",
+		budget:         budget,
+		modules:        createModules(),
+		random:         newRandom(),
+		output:         os.Stdout,
+	}
 }
 
-func (i *Interpreter) Interpret(expr core.Statement) (any, core.Error) {
-	return expr.Accept(i)
+// SetLimits bounds how much work scripts run by this interpreter may do.
+func (i *Interpreter) SetLimits(limits Limits) {
+	i.budget.limits = limits
 }
 
-func (i Interpreter) VisitExpressionStmt(stmt core.ExpressionStmt) (any, core.Error) {
-	evaluator := CreateEvaluatorWithEnvironment(&i.environment)
-	return evaluator.Evaluate(stmt.Expr)
+// SetScriptName sets the file name shown in runtime stack traces.
+func (i *Interpreter) SetScriptName(name string) {
+	i.scriptName = name
+}
+
+// SetOutput sets where `print` writes, standard output by default.
+func (i *Interpreter) SetOutput(output io.Writer) {
+	i.output = output
+}
+
+// SetDecimalContext sets the scale and rounding mode used when dividing decimals.
+func (i *Interpreter) SetDecimalContext(ctx decimal.Context) {
+	i.decimalContext = ctx
+}
+
+// Define adds a global variable, such as a native function, before scripts
+// run. Modules see these globals too.
+func (i *Interpreter) Define(name string, value any) error {
+	i.modules.builtins[name] = value
+	return i.globals().AddVariable(name, value)
 }
 
-func (i Interpreter) VisitPrintStmt(stmt core.PrintStmt) (any, core.Error) {
+// Global returns the value of a global variable.
+func (i *Interpreter) Global(name string) (any, bool) {
+	return i.globals().Lookup(name)
+}
+
+func (i *Interpreter) globals() *environment.Environment {
+	globals := &i.environment
+	for globals.Enclosing() != nil {
+		globals = globals.Enclosing()
+	}
+	return globals
+}
+
+// Call calls a Lox function or native from Go, as if it were called at the
+// line being run.
+func (i *Interpreter) Call(function Callable, arguments []any) (any, core.Error) {
+	arguments, err := checkArguments(function, arguments)
+	if err != nil {
+		return nil, core.Error{Line: i.budget.line, Err: err, ExitCode: 70}
+	}
+
+	return i.call(function, arguments, core.Token{Line: i.budget.line})
+}
+
+func (i *Interpreter) evaluator() Evaluator {
 	evaluator := CreateEvaluatorWithEnvironment(&i.environment)
-	value, err := evaluator.Evaluate(stmt.Expr)
-	if err.Err != nil {
-		return nil, err
+	evaluator.SetDecimalContext(i.decimalContext)
+	evaluator.interpreter = i
+	return evaluator
+}
+
+// Interpret executes a top-level statement. Runtime errors come back with
+// their stack trace filled in.
+func (i *Interpreter) Interpret(expr core.Statement) (any, core.Error) {
+	value, err := i.execute(expr)
+	if err.Err != nil && err.Trace == nil {
+		err.Trace = i.stackTrace(err.Line)
 	}
 
-	if value == nil {
-		fmt.Println("nil")
-		return nil, core.Error{}
+	return value, err
+}
+
+// InterpretContext is Interpret for untrusted scripts: it stops with an error
+// wrapping ErrExecutionCanceled once ctx is canceled or its deadline passes.
+func (i *Interpreter) InterpretContext(ctx context.Context, expr core.Statement) (any, core.Error) {
+	previousContext := i.budget.ctx
+	i.budget.ctx = ctx
+	defer func() { i.budget.ctx = previousContext }()
+
+	if err := ctx.Err(); err != nil {
+		return nil, core.Error{Err: fmt.Errorf("%w (%w)", ErrExecutionCanceled, err), ExitCode: 70}
 	}
 
-	_, isFloat := value.(float64)
-	if !isFloat {
-		fmt.Println(value)
-		return nil, core.Error{}
+	return i.Interpret(expr)
+}
+
+// execute runs a single statement, counting it against the step budget and
+// reporting it to the hook.
+func (i *Interpreter) execute(stmt core.Statement) (any, core.Error) {
+	line := core.StatementLine(stmt)
+	if err := i.budget.step(line); err != nil {
+		return nil, *err
 	}
+	if i.hook != nil {
+		if err := i.hook.BeforeStatement(i, stmt, line); err != nil {
+			return nil, core.Error{Line: line, Err: err, ExitCode: 70}
+		}
+	}
+
+	return stmt.Accept(i)
+}
+
+// call runs a callable inside a new frame, so errors raised within it are
+// traced back through the call site.
+func (i *Interpreter) call(function Callable, arguments []any, paren core.Token) (any, core.Error) {
+	maxCallDepth := i.budget.limits.MaxCallDepth
+	if maxCallDepth > 0 && len(i.frames) >= maxCallDepth {
+		return nil, core.Error{Line: paren.Line, Err: ErrCallDepthExceeded, ExitCode: 70}
+	}
+
+	script := i.CurrentScript()
+	if function, ok := function.(*LoxFunction); ok {
+		script = function.script
+	}
+
+	depth := len(i.frames)
+	i.frames = append(i.frames, callFrame{function: function.Name(), script: script, callLine: paren.Line, caller: i.environment})
+	defer func() { i.frames = i.frames[:depth] }()
+
+	value, err := function.Call(i, arguments)
+	if err.Err != nil && err.Line == 0 {
+		err.Line = paren.Line
+	}
+	if err.Err != nil && err.Trace == nil {
+		err.Trace = i.stackTrace(err.Line)
+	}
+
+	return value, err
+}
+
+// stackTrace walks the frames from the innermost one outwards. Each frame is
+// reported at the line it had reached: the error line for the innermost one
+// and the call site of its callee for the others.
+func (i *Interpreter) stackTrace(line int) []core.TraceFrame {
+	trace := []core.TraceFrame{}
+	for index := len(i.frames) - 1; index >= 0; index-- {
+		frame := i.frames[index]
+		trace = append(trace, core.TraceFrame{Function: frame.function, Script: frame.script, Line: line})
+		line = frame.callLine
+	}
+
+	return append(trace, core.TraceFrame{Function: scriptFrameName, Script: i.scriptName, Line: line})
+}
 
-	separated := strings.Split(fmt.Sprint(value), ".")
-	if len(separated) == 1 {
-		fmt.Println(value)
-		return nil, core.Error{}
+// executeBlock runs statements in env and restores the current environment
+// afterwards, whether they completed or not.
+func (i *Interpreter) executeBlock(statements []core.Statement, env environment.Environment) (any, core.Error) {
+	previousEnvironment := i.environment
+	i.environment = env
+	defer func() { i.environment = previousEnvironment }()
+
+	for _, statement := range statements {
+		if _, err := i.execute(statement); err.Err != nil {
+			return nil, err
+		}
 	}
 
-	decimalPart := separated[len(separated)-1]
-	decimalPart = strings.ReplaceAll(decimalPart, "0", "")
+	return nil, core.Error{}
+}
+
+func (i *Interpreter) VisitExpressionStmt(stmt core.ExpressionStmt) (any, core.Error) {
+	evaluator := i.evaluator()
+	return evaluator.Evaluate(stmt.Expr)
+}
 
-	if decimalPart == "" {
-		fmt.Printf("%.1f", value)
-		return nil, core.Error{}
+func (i *Interpreter) VisitPrintStmt(stmt core.PrintStmt) (any, core.Error) {
+	evaluator := i.evaluator()
+	value, err := evaluator.Evaluate(stmt.Expr)
+	if err.Err != nil {
+		return nil, err
 	}
 
+	fmt.Fprintln(i.output, Stringify(value))
 	return nil, core.Error{}
 }
 
-func (i Interpreter) VisitVarStmt(stmt core.VarStmt) (any, core.Error) {
-	evaluator := CreateEvaluatorWithEnvironment(&i.environment)
+func (i *Interpreter) VisitVarStmt(stmt core.VarStmt) (any, core.Error) {
+	evaluator := i.evaluator()
 	var value any
 	var err core.Error
 
@@ -72,22 +242,198 @@ func (i Interpreter) VisitVarStmt(stmt core.VarStmt) (any, core.Error) {
 		}
 	}
 
-	i.environment.AddVariable(stmt.Name.Lexeme, value)
+	if err := i.environment.AddVariable(stmt.Name.Lexeme, value); err != nil {
+		return nil, core.Error{Line: stmt.Name.Line, Err: err, ExitCode: 70}
+	}
 
 	return nil, err
 }
 
 func (i *Interpreter) VisitBlockStmt(stmt core.BlockStmt) (any, core.Error) {
-	previousEnvironment := i.environment
-	i.environment = environment.CreateEnvironmentWithEnclosing(&previousEnvironment)
+	enclosing := i.environment
+	env := environment.CreateEnvironmentWithEnclosing(&enclosing)
+	defer env.Release()
+	return i.executeBlock(stmt.Statements, env)
+}
 
-	for _, statement := range stmt.Statements {
-		_, err := statement.Accept(i)
+func (i *Interpreter) VisitFunctionStmt(stmt core.FunctionStmt) (any, core.Error) {
+	i.environment.Capture()
+	function := &LoxFunction{declaration: stmt, closure: i.environment, script: i.CurrentScript()}
+	if err := i.environment.AddVariable(stmt.Name.Lexeme, function); err != nil {
+		return nil, core.Error{Line: stmt.Name.Line, Err: err, ExitCode: 70}
+	}
+
+	return nil, core.Error{}
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt core.ReturnStmt) (any, core.Error) {
+	var value any
+	if stmt.Value != nil {
+		evaluator := i.evaluator()
+		var err core.Error
+		value, err = evaluator.Evaluate(stmt.Value)
 		if err.Err != nil {
 			return nil, err
 		}
 	}
 
-	i.environment = previousEnvironment
+	return nil, core.Error{Line: stmt.Keyword.Line, Err: returnSignal{value: value}, ExitCode: 70}
+}
+
+func (i *Interpreter) VisitThrowStmt(stmt core.ThrowStmt) (any, core.Error) {
+	evaluator := i.evaluator()
+	value, err := evaluator.Evaluate(stmt.Value)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	if loxError, ok := value.(*LoxError); ok && loxError.Line == 0 {
+		loxError.Line = stmt.Keyword.Line
+	}
+
+	return nil, core.Error{Line: stmt.Keyword.Line, Err: thrownValue{value: value}, ExitCode: 70}
+}
+
+// VisitTryStmt runs the try block, hands a catchable error to the catch
+// clause and always runs the finally clause. An error or return from the
+// finally clause replaces whatever the other clauses produced.
+func (i *Interpreter) VisitTryStmt(stmt core.TryStmt) (any, core.Error) {
+	enclosing := i.environment
+	body := environment.CreateEnvironmentWithEnclosing(&enclosing)
+	_, err := i.executeBlock(stmt.Body, body)
+	body.Release()
+
+	if err.Err != nil && stmt.HasCatch && isCatchable(err) {
+		catchEnvironment := environment.CreateEnvironmentWithEnclosing(&enclosing)
+		defer catchEnvironment.Release()
+		value, caughtErr := i.caughtValue(err)
+		if caughtErr.Err != nil {
+			return nil, caughtErr
+		}
+		if addErr := catchEnvironment.AddVariable(stmt.CatchName.Lexeme, value); addErr != nil {
+			return nil, core.Error{Line: stmt.CatchName.Line, Err: addErr, ExitCode: 70}
+		}
+		_, err = i.executeBlock(stmt.CatchBody, catchEnvironment)
+	}
+
+	if stmt.FinallyBody != nil {
+		finally := environment.CreateEnvironmentWithEnclosing(&enclosing)
+		defer finally.Release()
+		_, finallyErr := i.executeBlock(stmt.FinallyBody, finally)
+		if finallyErr.Err != nil {
+			return nil, finallyErr
+		}
+	}
+
+	return nil, err
+}
+
+func (i *Interpreter) VisitIfStmt(stmt core.IfStmt) (any, core.Error) {
+	evaluator := i.evaluator()
+	condition, err := evaluator.Evaluate(stmt.Condition)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	i.branch(stmt.Keyword, isTruthy(condition))
+	if isTruthy(condition) {
+		return i.execute(stmt.ThenBranch)
+	}
+	if stmt.ElseBranch != nil {
+		return i.execute(stmt.ElseBranch)
+	}
+
 	return nil, core.Error{}
 }
+
+func (i *Interpreter) VisitWhileStmt(stmt core.WhileStmt) (any, core.Error) {
+	evaluator := i.evaluator()
+	for {
+		condition, err := evaluator.Evaluate(stmt.Condition)
+		if err.Err != nil {
+			return nil, err
+		}
+		i.branch(stmt.Keyword, isTruthy(condition))
+		if !isTruthy(condition) {
+			return nil, core.Error{}
+		}
+
+		if _, err := i.execute(stmt.Body); err.Err != nil {
+			return nil, err
+		}
+	}
+}
+
+// VisitForStmt runs the loop in its own scope, so a variable declared by the
+// initializer is not visible after the loop.
+func (i *Interpreter) VisitForStmt(stmt core.ForStmt) (any, core.Error) {
+	enclosing := i.environment
+	i.environment = environment.CreateEnvironmentWithEnclosing(&enclosing)
+	defer func() {
+		i.environment.Release()
+		i.environment = enclosing
+	}()
+
+	return i.runForLoop(stmt)
+}
+
+func (i *Interpreter) runForLoop(stmt core.ForStmt) (any, core.Error) {
+	if stmt.Initializer != nil {
+		if _, err := i.execute(stmt.Initializer); err.Err != nil {
+			return nil, err
+		}
+	}
+
+	evaluator := i.evaluator()
+	for {
+		if stmt.Condition != nil {
+			condition, err := evaluator.Evaluate(stmt.Condition)
+			if err.Err != nil {
+				return nil, err
+			}
+			i.branch(stmt.Keyword, isTruthy(condition))
+			if !isTruthy(condition) {
+				return nil, core.Error{}
+			}
+		}
+
+		if _, err := i.execute(stmt.Body); err.Err != nil {
+			return nil, err
+		}
+
+		if stmt.Increment != nil {
+			if _, err := evaluator.Evaluate(stmt.Increment); err.Err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Stringify formats a runtime value the way `print` shows it. Decimals format
+// themselves from their exact digits, so they never show float artifacts.
+func Stringify(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return formatNumber(value)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// formatNumber shows a number with the fewest digits that read back as the
+// same number, as the evaluate command does: 3.5 prints as 3.5 and 3.0 as 3.
+// Infinities and NaN show as the constants scripts write them with.
+func formatNumber(number float64) string {
+	switch {
+	case math.IsNaN(number):
+		return "NAN"
+	case math.IsInf(number, 1):
+		return "INF"
+	case math.IsInf(number, -1):
+		return "-INF"
+	}
+
+	return fmt.Sprint(number)
+}
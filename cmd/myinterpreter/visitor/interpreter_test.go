@@ -0,0 +1,245 @@
+package visitor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+func TestRuntimeErrorCarriesStackTrace(t *testing.T) {
+	program := `
+fun inner(x) {
+  return x + nil;
+}
+fun outer() {
+  return inner(1);
+}
+outer();
+`
+	_, err := run(t, program, "nil")
+	if err.Err == nil {
+		t.Fatal("was expecting a runtime error, but didn't get one")
+	}
+
+	expected := []core.TraceFrame{
+		{Function: "inner", Script: "<input>", Line: 3},
+		{Function: "outer", Script: "<input>", Line: 6},
+		{Function: "<script>", Script: "<input>", Line: 8},
+	}
+	if !reflect.DeepEqual(err.Trace, expected) {
+		t.Fatalf("expecting trace %v, but got: %v", expected, err.Trace)
+	}
+
+	var goErr error = err
+	if !errors.Is(goErr, err.Err) {
+		t.Fatal("expecting the Go error to unwrap to the underlying error")
+	}
+}
+
+func TestStackTraceFormat(t *testing.T) {
+	err := core.Error{Trace: []core.TraceFrame{
+		{Function: "foo", Script: "script.lox", Line: 12},
+		{Function: "<script>", Script: "script.lox", Line: 20},
+	}}
+
+	expected := "    at foo (script.lox:12)\n    at <script> (script.lox:20)"
+	if err.StackTrace() != expected {
+		t.Fatalf("expecting %q, but got: %q", expected, err.StackTrace())
+	}
+}
+
+func TestFramesArePoppedAfterCalls(t *testing.T) {
+	_, err := run(t, "fun ok() { return 1; }\nok();\nok();\nvar x = 1 + nil;", "nil")
+	if err.Err == nil {
+		t.Fatal("was expecting a runtime error, but didn't get one")
+	}
+
+	expected := []core.TraceFrame{{Function: "<script>", Script: "<input>", Line: 4}}
+	if !reflect.DeepEqual(err.Trace, expected) {
+		t.Fatalf("expecting trace %v, but got: %v", expected, err.Trace)
+	}
+}
+
+func TestCatchRuntimeErrorAsErrorObject(t *testing.T) {
+	program := `
+var message;
+var line;
+try {
+  var x = 1 + nil;
+} catch (e) {
+  message = e.message;
+  line = e.line;
+}
+`
+	value, err := run(t, program, "message")
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if value != "Operands must be two numbers or two strings." {
+		t.Fatalf("expecting the caught error message, but got: %v", value)
+	}
+
+	value, _ = run(t, program, "line")
+	if value != 5.0 {
+		t.Fatalf("expecting the caught error line to be 5, but got: %v", value)
+	}
+}
+
+func TestThrowAndFinallyOrdering(t *testing.T) {
+	program := `
+var log = "";
+fun risky() {
+  try {
+    log += "try ";
+    throw "boom";
+  } finally {
+    log += "finally ";
+  }
+}
+try {
+  risky();
+} catch (e) {
+  log += "caught " + e;
+}
+`
+	value, err := run(t, program, "log")
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if value != "try finally caught boom" {
+		t.Fatalf("expecting clauses to run in order, but got: %v", value)
+	}
+}
+
+func TestFinallyRunsOnReturn(t *testing.T) {
+	program := `
+var cleaned = false;
+fun f() {
+  try {
+    return 1;
+  } finally {
+    cleaned = true;
+  }
+}
+var result = f();
+`
+	value, err := run(t, program, "cleaned ? result : nil")
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if value != 1.0 {
+		t.Fatalf("expecting the return value to survive the finally clause, but got: %v", value)
+	}
+}
+
+func TestUncaughtThrowKeepsRuntimeExitCode(t *testing.T) {
+	_, err := run(t, `throw Error("bad input");`, "nil")
+	if err.Err == nil {
+		t.Fatal("was expecting an uncaught error, but didn't get one")
+	}
+	if err.ExitCode != 70 || err.Err.Error() != "bad input" || err.Line != 1 {
+		t.Fatalf("expecting an exit code 70 error 'bad input' at line 1, but got: %v (exit %d)", err, err.ExitCode)
+	}
+}
+
+// interpretSource runs source on an existing interpreter, the way the REPL
+// feeds it one input at a time, and returns the first runtime error.
+func interpretSource(t *testing.T, interpreter *Interpreter, source string) core.Error {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(source))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors, but got: %v", errs[0].Err)
+	}
+
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any parse errors, but got: %v", parseErr.Err)
+	}
+
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			return err
+		}
+	}
+
+	return core.Error{}
+}
+
+func TestScopeIsRestoredAfterRuntimeErrors(t *testing.T) {
+	sources := map[string]string{
+		"block":    "{ var a = \"inner\"; { var b = 1 + nil; } }",
+		"function": "fun f(a) { { var b = a + nil; } } f(\"inner\");",
+		"for":      "for (var a = \"inner\"; true;) { var b = a + nil; }",
+		"while":    "while (true) { var a = \"inner\"; var b = a + nil; }",
+		"if":       "if (true) { var a = \"inner\"; var b = a + nil; }",
+		"try":      "try { var a = \"inner\"; var b = a + nil; } finally { var c = 1; }",
+		"catch":    "try { throw 1; } catch (a) { var b = a + nil; }",
+	}
+
+	for name, source := range sources {
+		interpreter := CreateInterpreter()
+		if err := interpretSource(t, &interpreter, `var a = "global";`); err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", name, err.Err)
+		}
+
+		if err := interpretSource(t, &interpreter, source); err.Err == nil {
+			t.Fatalf("%s: was expecting a runtime error, but didn't get one", name)
+		}
+
+		if err := interpretSource(t, &interpreter, `var b = a;`); err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors after resuming, but got: %v", name, err.Err)
+		}
+
+		evaluator := interpreter.evaluator()
+		value, err := evaluator.Evaluate(parseExpression(t, "b"))
+		if err.Err != nil || value != "global" {
+			t.Fatalf("%s: expecting to resume in the global scope, but got: %v (%v)", name, value, err.Err)
+		}
+		if len(interpreter.frames) != 0 {
+			t.Fatalf("%s: expecting no call frames left after the error, but got: %d", name, len(interpreter.frames))
+		}
+	}
+}
+
+func TestTraceAfterResumingHasNoStaleFrames(t *testing.T) {
+	interpreter := CreateInterpreter()
+	interpretSource(t, &interpreter, "fun f() { return 1 + nil; }\nf();")
+
+	err := interpretSource(t, &interpreter, "var x = 1 + nil;")
+	expected := []core.TraceFrame{{Function: "<script>", Script: "<input>", Line: 1}}
+	if !reflect.DeepEqual(err.Trace, expected) {
+		t.Fatalf("expecting trace %v, but got: %v", expected, err.Trace)
+	}
+}
+func TestStringifyNumbers(t *testing.T) {
+	price, err := decimal.Parse("1.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		value    any
+		expected string
+	}{
+		{3.0, "3"},
+		{3.5, "3.5"},
+		{10.40, "10.4"},
+		{-2.25, "-2.25"},
+		{100000000.0, "1e+08"},
+		{price, "1.10"},
+		{nil, "nil"},
+	}
+
+	// whole numbers print as they always did; fractions used to print nothing
+	for _, c := range cases {
+		if found := Stringify(c.value); found != c.expected {
+			t.Fatalf("expecting %v to print as %s, but got: %s", c.value, c.expected, found)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package visitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+// maxJSONDepth bounds the nesting of arrays and objects, so malformed or
+// cyclic data fails cleanly instead of exhausting the Go stack.
+const maxJSONDepth = 1000
+
+// maxJSONIndent is the widest indentation json.stringify accepts.
+const maxJSONIndent = 10
+
+// jsonNamespace is the `json` global. Objects become records, arrays lists,
+// numbers floats, and null nil; the other way round, decimals are written
+// with their exact digits.
+func jsonNamespace() *Namespace {
+	return &Namespace{name: "json", members: map[string]any{
+		"parse":     &NativeFunction{name: "parse", arity: 1, function: parseJSON},
+		"stringify": &NativeFunction{name: "stringify", arity: 2, optional: 1, function: stringifyJSON},
+	}}
+}
+
+// parseJSON implements `json.parse(text)`.
+func parseJSON(interpreter *Interpreter, arguments []any) (any, error) {
+	text, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("json.parse expects a string.")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	value, err := (&jsonDecoder{interpreter: interpreter, decoder: decoder}).decode(0)
+	if err != nil {
+		return nil, invalidJSON(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("Invalid JSON: unexpected data after the value at offset %d.", decoder.InputOffset())
+	}
+	return value, nil
+}
+
+// invalidJSON describes an error of the decoder, whose own messages lack
+// the position of syntax errors.
+func invalidJSON(err error) error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("Invalid JSON at offset %d: %v.", syntaxErr.Offset, syntaxErr)
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return fmt.Errorf("Invalid JSON: unexpected end of input.")
+	case isLimitError(err):
+		return err
+	}
+	return fmt.Errorf("Invalid JSON: %v", err)
+}
+
+// jsonDecoder builds Lox values from JSON, checking the memory they take
+// so far against the quota as it goes.
+type jsonDecoder struct {
+	interpreter *Interpreter
+	decoder     *json.Decoder
+	size        int
+}
+
+// grow accounts for bytes more of the value being built.
+func (d *jsonDecoder) grow(bytes int) error {
+	d.size += bytes
+	return d.interpreter.budget.reserve(d.size)
+}
+
+func (d *jsonDecoder) decode(depth int) (any, error) {
+	if depth > maxJSONDepth {
+		return nil, fmt.Errorf("nesting deeper than %d levels.", maxJSONDepth)
+	}
+
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			return d.decodeArray(depth)
+		}
+		return d.decodeObject(depth)
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("number %s is out of range.", value)
+		}
+		return number, nil
+	case string:
+		if err := d.grow(len(value)); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	// booleans and nil are Lox values already
+	return token, nil
+}
+
+func (d *jsonDecoder) decodeArray(depth int) (any, error) {
+	list := &List{Elements: []any{}}
+	for d.decoder.More() {
+		element, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.grow(listEntrySize); err != nil {
+			return nil, err
+		}
+		list.Elements = append(list.Elements, element)
+	}
+
+	_, err := d.decoder.Token()
+	return list, err
+}
+
+func (d *jsonDecoder) decodeObject(depth int) (any, error) {
+	record := CreateRecord()
+	for d.decoder.More() {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := token.(string)
+
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.grow(listEntrySize + len(key)); err != nil {
+			return nil, err
+		}
+		record.Set(key, value)
+	}
+
+	_, err := d.decoder.Token()
+	return record, err
+}
+
+// stringifyJSON implements `json.stringify(value)` and
+// `json.stringify(value, indent)`. Without an indent, or with nil or 0, the
+// text is on one line.
+func stringifyJSON(interpreter *Interpreter, arguments []any) (any, error) {
+	indent := ""
+	if arguments[1] != nil {
+		spaces, ok := integer(arguments[1])
+		if !ok || spaces < 0 || spaces > maxJSONIndent {
+			return nil, fmt.Errorf("json.stringify expects an indent of 0 to %d spaces, or nil.", maxJSONIndent)
+		}
+		indent = strings.Repeat(" ", spaces)
+	}
+
+	var b strings.Builder
+	if err := writeJSON(&b, arguments[0], indent, "\n", 0); err != nil {
+		return nil, err
+	}
+	return newString(interpreter, b.String())
+}
+
+// writeJSON writes value, starting its nested lines with newline, which
+// holds the indentation reached so far.
+func writeJSON(b *strings.Builder, value any, indent string, newline string, depth int) error {
+	if depth > maxJSONDepth {
+		return fmt.Errorf("Can't convert values nested deeper than %d levels to JSON.", maxJSONDepth)
+	}
+
+	switch value := value.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		fmt.Fprint(b, value)
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("Can't convert %s to JSON.", Stringify(value))
+		}
+		text, _ := json.Marshal(value)
+		b.Write(text)
+	case decimal.Decimal:
+		b.WriteString(value.String())
+	case string:
+		b.WriteString(quoteJSON(value))
+	case *List:
+		if len(value.Elements) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteString("[")
+		for index, element := range value.Elements {
+			if index > 0 {
+				b.WriteString(",")
+			}
+			if indent != "" {
+				b.WriteString(newline + indent)
+			}
+			if err := writeJSON(b, element, indent, newline+indent, depth+1); err != nil {
+				return err
+			}
+		}
+		if indent != "" {
+			b.WriteString(newline)
+		}
+		b.WriteString("]")
+	case *Record:
+		if len(value.keys) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{")
+		for index, key := range value.keys {
+			if index > 0 {
+				b.WriteString(",")
+			}
+			if indent != "" {
+				b.WriteString(newline + indent)
+			}
+			b.WriteString(quoteJSON(key) + ":")
+			if indent != "" {
+				b.WriteString(" ")
+			}
+			if err := writeJSON(b, value.fields[key], indent, newline+indent, depth+1); err != nil {
+				return err
+			}
+		}
+		if indent != "" {
+			b.WriteString(newline)
+		}
+		b.WriteString("}")
+	default:
+		return fmt.Errorf("Can't convert %s to JSON.", Stringify(value))
+	}
+	return nil
+}
+
+// quoteJSON quotes a string for JSON, leaving <, > and & as they are.
+func quoteJSON(str string) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(str)
+	return strings.TrimSuffix(b.String(), "\n")
+}
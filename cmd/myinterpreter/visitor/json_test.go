@@ -0,0 +1,138 @@
+package visitor
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	interpreter := CreateInterpreter()
+	input := `{"name": "lox", "tags": [1, 2.5, true, null, {}], "nested": {"html": "<a & b>", "empty": []}}`
+
+	value, err := parseJSON(&interpreter, []any{input})
+	if err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err)
+	}
+
+	compact, err := stringifyJSON(&interpreter, []any{value, nil})
+	if err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err)
+	}
+	expected := `{"name":"lox","tags":[1,2.5,true,null,{}],"nested":{"html":"<a & b>","empty":[]}}`
+	if compact != expected {
+		t.Fatalf("expecting %s, but got: %s", expected, compact)
+	}
+
+	indented, _ := stringifyJSON(&interpreter, []any{value.(*Record).fields["tags"], 2.0})
+	if indented != "[\n  1,\n  2.5,\n  true,\n  null,\n  {}\n]" {
+		t.Fatalf("expecting an indented array, but got: %q", indented)
+	}
+}
+
+func TestJSONValuesInScripts(t *testing.T) {
+	interpreter := CreateInterpreter()
+	var output strings.Builder
+	interpreter.SetOutput(&output)
+	value, _ := parseJSON(&interpreter, []any{`{"name": "lox", "tags": ["a", 3], "name": "again"}`})
+	interpreter.Define("data", value)
+
+	tokens, _ := scanner.ScanFile([]byte(`print data.name; print data.tags.get(1); print keys(data); print data;`))
+	statements, _ := parser.Parse(tokens)
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+		}
+	}
+
+	expected := "again\n3\n[\"name\", \"tags\"]\n{\"name\": \"again\", \"tags\": [\"a\", 3]}\n"
+	if output.String() != expected {
+		t.Fatalf("expecting output %q, but got: %q", expected, output.String())
+	}
+}
+
+func TestRecordFieldsByKey(t *testing.T) {
+	interpreter := CreateInterpreter()
+	value, _ := parseJSON(&interpreter, []any{`{"first name": "Ada", "get": 1, "empty": null}`})
+	interpreter.Define("data", value)
+	evaluator := interpreter.evaluator()
+
+	cases := map[string]any{
+		`get(data, "first " + "name")`: "Ada",
+		`get(data, "get")`:             1.0,
+		`get(data, "empty")`:           nil,
+		`get(data, "missing")`:         nil,
+		`json.stringify(data)`:         `{"first name":"Ada","get":1,"empty":null}`,
+		`json.stringify(data, nil)`:    `{"first name":"Ada","get":1,"empty":null}`,
+		`json.stringify(data.get, 0)`:  "1",
+	}
+	for expression, expected := range cases {
+		value, err := evaluator.Evaluate(parseExpression(t, expression))
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", expression, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", expression, expected, value)
+		}
+	}
+
+	errorCases := map[string]string{
+		`get(data, 1)`:               "get expects a record and a string key.",
+		`get(nil, "a")`:              "get expects a record and a string key.",
+		`json.stringify()`:           "Expected 1 to 2 arguments but got 0.",
+		`json.stringify(data, 2, 2)`: "Expected 1 to 2 arguments but got 3.",
+		`keys()`:                     "Expected 1 arguments but got 0.",
+	}
+	for expression, expected := range errorCases {
+		_, err := evaluator.Evaluate(parseExpression(t, expression))
+		if err.Err == nil || err.Err.Error() != expected {
+			t.Fatalf("%s: expecting error %q, but got: %v", expression, expected, err.Err)
+		}
+	}
+
+	// a Go host can leave optional arguments out too
+	compact, err := interpreter.Call(jsonNamespace().members["stringify"].(*NativeFunction), []any{1.5})
+	if err.Err != nil || compact != "1.5" {
+		t.Fatalf("expecting json.stringify(1.5) called from Go to be 1.5, but got: %v (%v)", compact, err.Err)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	interpreter := CreateInterpreter()
+
+	parseCases := map[string]string{
+		`{"a": }`:                 "Invalid JSON at offset 7: missing value after object key.",
+		`[1, 2`:                   "Invalid JSON at offset 5: unexpected end of JSON input.",
+		``:                        "Invalid JSON: unexpected end of input.",
+		`1 2`:                     "Invalid JSON: unexpected data after the value at offset 3.",
+		`1e999`:                   "Invalid JSON: number 1e999 is out of range.",
+		strings.Repeat("[", 2000): "Invalid JSON: nesting deeper than 1000 levels.",
+	}
+	for input, expected := range parseCases {
+		_, err := parseJSON(&interpreter, []any{input})
+		if err == nil || err.Error() != expected {
+			t.Fatalf("%.20s: expecting error %q, but got: %v", input, expected, err)
+		}
+	}
+
+	function := &LoxFunction{}
+	function.declaration.Name.Lexeme = "f"
+	stringifyCases := []struct {
+		value    any
+		indent   any
+		expected string
+	}{
+		{&List{Elements: []any{function}}, nil, "Can't convert <fn f> to JSON."},
+		{math.NaN(), nil, "Can't convert NAN to JSON."},
+		{1.0, 11.0, "json.stringify expects an indent of 0 to 10 spaces, or nil."},
+	}
+	for _, c := range stringifyCases {
+		_, err := stringifyJSON(&interpreter, []any{c.value, c.indent})
+		if err == nil || err.Error() != c.expected {
+			t.Fatalf("expecting error %q, but got: %v", c.expected, err)
+		}
+	}
+}
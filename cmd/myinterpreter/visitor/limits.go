@@ -0,0 +1,172 @@
+package visitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+// Errors raised when a script runs into one of the interpreter's Limits or
+// its context is done. They end the script: try/catch in Lox can't handle
+// them, but hosts can tell them apart with errors.Is on the returned
+// core.Error.
+var (
+	ErrStepLimitExceeded   = errors.New("Step limit exceeded.")
+	ErrCallDepthExceeded   = errors.New("Maximum call depth exceeded.")
+	ErrExecutionCanceled   = errors.New("Execution canceled.")
+	ErrMemoryLimitExceeded = errors.New("Memory limit exceeded.")
+)
+
+// Limits bounds the work a script may do. A zero field means no limit.
+type Limits struct {
+	// MaxSteps is the number of statements and expressions the interpreter
+	// may evaluate over its lifetime.
+	MaxSteps int
+	// MaxCallDepth is the number of nested function calls allowed.
+	MaxCallDepth int
+	// MaxMemory is the number of bytes the variables of a script may keep
+	// alive at once: the variables themselves and the strings, decimals and
+	// objects they hold, a value held by several variables counting once for
+	// each. A value being built must also fit next to them. The bytes of a
+	// variable are given back when it is overwritten or its scope ends.
+	MaxMemory int
+}
+
+// DefaultLimits keeps runaway recursion from overflowing the Go stack.
+var DefaultLimits = Limits{MaxCallDepth: 10000}
+
+// cancellationCheckInterval is how many steps run between checks of the
+// context, which is cheap but not free.
+const cancellationCheckInterval = 1024
+
+// maxAllocation bounds every single value scripts build, with or without a
+// memory limit, so a huge string or decimal fails cleanly instead of taking
+// the host's memory.
+const maxAllocation = 1 << 30
+
+// Approximate sizes, in bytes, charged for runtime objects on top of the
+// strings they hold.
+const (
+	functionSize    = 128
+	errorObjectSize = 64
+)
+
+// budget is shared by every copy of an Interpreter, so steps counted while
+// running nested statements are never lost.
+type budget struct {
+	limits Limits
+	ctx    context.Context
+	steps  int
+	// allocated is the number of bytes charged through Allocate and not
+	// released yet.
+	allocated int
+	// line is the last source line seen, used to report where a limit hit.
+	line int
+}
+
+func (b *budget) step(line int) *core.Error {
+	if line > 0 {
+		b.line = line
+	}
+
+	b.steps++
+	if b.limits.MaxSteps > 0 && b.steps > b.limits.MaxSteps {
+		return &core.Error{Line: b.line, Err: ErrStepLimitExceeded, ExitCode: 70}
+	}
+
+	if b.ctx != nil && b.steps%cancellationCheckInterval == 0 {
+		if err := b.ctx.Err(); err != nil {
+			return &core.Error{Line: b.line, Err: fmt.Errorf("%w (%w)", ErrExecutionCanceled, err), ExitCode: 70}
+		}
+	}
+
+	return nil
+}
+
+// Allocate charges bytes against the memory quota until they are released.
+// Environments call it for their variables.
+func (b *budget) Allocate(bytes int) error {
+	if err := b.reserve(bytes); err != nil {
+		return err
+	}
+
+	b.allocated += bytes
+	return nil
+}
+
+func (b *budget) Release(bytes int) {
+	b.allocated -= bytes
+}
+
+// reserve checks that a value of bytes fits next to the memory charged so
+// far. It is called before the value is built, so an oversized string is
+// never allocated; the value is charged once a variable holds it.
+func (b *budget) reserve(bytes int) error {
+	if bytes > maxAllocation {
+		return ErrMemoryLimitExceeded
+	}
+	if b.limits.MaxMemory > 0 && bytes > b.limits.MaxMemory-b.allocated {
+		return ErrMemoryLimitExceeded
+	}
+	return nil
+}
+
+func (b *budget) Size(value any) int {
+	return sizeOf(value)
+}
+
+// sizeOf approximates the bytes a variable holding value keeps alive.
+func sizeOf(value any) int {
+	switch value := value.(type) {
+	case string:
+		return len(value)
+	case decimal.Decimal:
+		return value.Size()
+	case *List:
+		return value.size()
+	case *Record:
+		return value.size()
+	case *LoxFunction:
+		return functionSize
+	case *LoxError:
+		return errorObjectSize + len(value.Message)
+	}
+	return 0
+}
+
+func isLimitError(err error) bool {
+	return errors.Is(err, ErrStepLimitExceeded) ||
+		errors.Is(err, ErrCallDepthExceeded) ||
+		errors.Is(err, ErrExecutionCanceled) ||
+		errors.Is(err, ErrMemoryLimitExceeded)
+}
+
+// expressionLine returns the source line of expressions that carry a token,
+// or 0 for the ones that don't.
+func expressionLine(expr core.Expression) int {
+	switch node := expr.(type) {
+	case core.Binary:
+		return node.Operator.Line
+	case core.Unary:
+		return node.Operator.Line
+	case core.Variable:
+		return node.Name.Line
+	case core.Assign:
+		return node.Name.Line
+	case core.CompoundAssign:
+		return node.Name.Line
+	case core.Increment:
+		return node.Name.Line
+	case core.Logical:
+		return node.Operator.Line
+	case core.Call:
+		return node.Paren.Line
+	case core.Get:
+		return node.Name.Line
+	}
+
+	return 0
+}
@@ -0,0 +1,214 @@
+package visitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+func runLimited(t *testing.T, ctx context.Context, limits Limits, program string) core.Error {
+	t.Helper()
+
+	tokens, errs := scanner.ScanFile([]byte(program))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors, but got: %v", errs[0].Err)
+	}
+
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any parse errors, but got: %v", parseErr.Err)
+	}
+
+	interpreter := CreateInterpreter()
+	interpreter.SetLimits(limits)
+	for _, statement := range statements {
+		if _, err := interpreter.InterpretContext(ctx, statement); err.Err != nil {
+			return err
+		}
+	}
+
+	return core.Error{}
+}
+
+func TestStepLimitStopsInfiniteLoop(t *testing.T) {
+	err := runLimited(t, context.Background(), Limits{MaxSteps: 10000}, "while (true) {}")
+	if !errors.Is(err, ErrStepLimitExceeded) {
+		t.Fatalf("expecting a step limit error, but got: %v", err.Err)
+	}
+}
+
+func TestStepLimitAllowsScriptsWithinBudget(t *testing.T) {
+	err := runLimited(t, context.Background(), Limits{MaxSteps: 10000}, "var i = 0; while (i < 10) i++;")
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+}
+
+func TestContextDeadlineStopsInfiniteLoop(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := runLimited(t, ctx, Limits{}, "while (true) {}")
+	if !errors.Is(err, ErrExecutionCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expecting a canceled execution error, but got: %v", err.Err)
+	}
+}
+
+func TestCanceledContextStopsBeforeRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := runLimited(t, ctx, Limits{}, "print 1;")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expecting a canceled execution error, but got: %v", err.Err)
+	}
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	err := runLimited(t, context.Background(), Limits{MaxCallDepth: 50}, "fun f() { f(); } f();")
+	if !errors.Is(err, ErrCallDepthExceeded) {
+		t.Fatalf("expecting a call depth error, but got: %v", err.Err)
+	}
+	if len(err.Trace) != 51 {
+		t.Fatalf("expecting 50 frames plus the script, but got: %d", len(err.Trace))
+	}
+}
+
+func TestDefaultCallDepthPreventsStackOverflow(t *testing.T) {
+	err := runLimited(t, context.Background(), DefaultLimits, "fun f() { f(); } f();")
+	if !errors.Is(err, ErrCallDepthExceeded) {
+		t.Fatalf("expecting a call depth error, but got: %v", err.Err)
+	}
+}
+
+func TestScriptsCannotCatchLimitErrors(t *testing.T) {
+	program := `
+var caught = false;
+try {
+  while (true) {}
+} catch (e) {
+  caught = true;
+}
+`
+	err := runLimited(t, context.Background(), Limits{MaxSteps: 1000}, program)
+	if !errors.Is(err, ErrStepLimitExceeded) {
+		t.Fatalf("expecting the step limit error to reach the host, but got: %v", err.Err)
+	}
+}
+
+func TestMemoryLimitStopsStringDoubling(t *testing.T) {
+	program := `
+var s = "x";
+while (true) {
+  try {
+    s = s + s;
+  } catch (e) {}
+}
+`
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 1 << 20}, program)
+	if !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting a memory limit error, but got: %v", err.Err)
+	}
+	if err.Line != 5 {
+		t.Fatalf("expecting the error at the concatenation on line 5, but got: %d", err.Line)
+	}
+}
+
+func TestMemoryLimitCountsVariables(t *testing.T) {
+	program := `
+fun f(a, b, c) {
+  var d = a;
+  f(a, b, c);
+}
+f(1, 2, 3);
+`
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 64 * 1024}, program)
+	if !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting a memory limit error, but got: %v", err.Err)
+	}
+}
+
+func TestMemoryLimitCountsLiveMemoryOnly(t *testing.T) {
+	program := `
+fun twice(word) {
+  var doubled = word + word;
+  return len(doubled);
+}
+var total = 0;
+var last = "";
+var i = 0;
+while (i < 200000) {
+  var word = "abcdefghij";
+  last = word + word;
+  total = total + twice(word);
+  i++;
+}
+`
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 1000000}, program)
+	if err.Err != nil {
+		t.Fatalf("expecting memory to be given back at the end of scopes, but got: %v", err.Err)
+	}
+}
+
+func TestMemoryLimitKeepsCapturedScopes(t *testing.T) {
+	program := `
+var f = nil;
+while (true) {
+  var s = "0123456789012345678901234567890123456789";
+  var previous = f;
+  fun g() { return previous; }
+  f = g;
+}
+`
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 1 << 20, MaxSteps: 10000000}, program)
+	if !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting scopes kept alive by closures to stay charged, but got: %v", err.Err)
+	}
+}
+
+func TestMemoryLimitChargesDecimalPowersUpFront(t *testing.T) {
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 64 * 1024}, "var x = 10d ** 100000;")
+	if !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting a memory limit error, but got: %v", err.Err)
+	}
+}
+
+func TestHugeValuesFailWithoutAMemoryLimit(t *testing.T) {
+	programs := []string{
+		"var x = 10d ** 1000000000;",
+		"var x = 2d ** -1000000000;",
+		`var x = repeat("ab", 1000000000);`,
+	}
+
+	for _, program := range programs {
+		err := runLimited(t, context.Background(), Limits{}, program)
+		if !errors.Is(err, ErrMemoryLimitExceeded) {
+			t.Fatalf("%s: expecting a memory limit error, but got: %v", program, err.Err)
+		}
+	}
+
+	b := &budget{}
+	if err := b.Allocate(maxAllocation + 1); !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting allocations above %d bytes to fail, but got: %v", maxAllocation, err)
+	}
+	if err := runLimited(t, context.Background(), Limits{}, "var x = 1.01d ** 365;"); err.Err != nil {
+		t.Fatalf("was not expecting any errors for a small power, but got: %v", err.Err)
+	}
+}
+
+func TestMemoryLimitAllowsScriptsWithinQuota(t *testing.T) {
+	program := `
+var s = "";
+for (var i = 0; i < 100; i++) s += "ab";
+var message = Error(s).message;
+`
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 1 << 20}, program)
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+}
@@ -0,0 +1,72 @@
+package visitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// listEntrySize approximates the memory taken by one element of a list.
+const listEntrySize = 16
+
+// List is an ordered collection built by natives such as `split`. Scripts
+// read its `length` and its elements with `list.get(index)`.
+type List struct {
+	Elements []any
+	// bytes caches size, since scripts can't change a list.
+	bytes int
+}
+
+// size approximates the memory taken by the list and its elements.
+func (l *List) size() int {
+	if l.bytes == 0 {
+		l.bytes = len(l.Elements) * listEntrySize
+		for _, element := range l.Elements {
+			l.bytes += sizeOf(element)
+		}
+	}
+	return l.bytes
+}
+
+func (l *List) GetProperty(name string) (any, bool) {
+	switch name {
+	case "length":
+		return float64(len(l.Elements)), true
+	case "get":
+		return &NativeFunction{name: "get", arity: 1, function: func(interpreter *Interpreter, arguments []any) (any, error) {
+			index, ok := integer(arguments[0])
+			if !ok {
+				return nil, fmt.Errorf("get expects an integer index.")
+			}
+			if index < 0 || index >= len(l.Elements) {
+				return nil, fmt.Errorf("Index %d out of range for a list of length %d.", index, len(l.Elements))
+			}
+			return l.Elements[index], nil
+		}}, true
+	}
+
+	return nil, false
+}
+
+// String shows the elements the way assertion failures do, with strings in
+// quotes.
+func (l *List) String() string {
+	elements := make([]string, len(l.Elements))
+	for index, element := range l.Elements {
+		elements[index] = quote(element)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// maxSafeInteger is the largest integer every smaller one of which a float64
+// holds exactly.
+const maxSafeInteger = 1 << 53
+
+// integer converts a number argument holding a whole value to an int.
+func integer(value any) (int, bool) {
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) || math.Abs(number) > maxSafeInteger {
+		return 0, false
+	}
+	return int(number), true
+}
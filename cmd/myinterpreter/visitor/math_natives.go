@@ -0,0 +1,183 @@
+package visitor
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+// mathConstants are the numbers defined next to the natives.
+func mathConstants() []constant {
+	return []constant{
+		{name: "PI", value: math.Pi},
+		{name: "E", value: math.E},
+		{name: "INF", value: math.Inf(1)},
+		{name: "NAN", value: math.NaN()},
+	}
+}
+
+// mathNatives wrap Go's math package. Results outside a function's domain,
+// such as sqrt(-1), are NaN rather than errors, as in Go. Rounding, abs, min
+// and max keep decimals exact; the other functions convert decimals to the
+// nearest float and return a float.
+func mathNatives() []*NativeFunction {
+	return []*NativeFunction{
+		unary("sqrt", math.Sqrt),
+		exact("floor", math.Floor, func(d decimal.Decimal) decimal.Decimal { return d.Round(0, decimal.Floor) }),
+		exact("ceil", math.Ceil, func(d decimal.Decimal) decimal.Decimal { return d.Round(0, decimal.Ceiling) }),
+		exact("round", math.Round, func(d decimal.Decimal) decimal.Decimal { return d.Round(0, decimal.HalfUp) }),
+		exact("abs", math.Abs, decimal.Decimal.Abs),
+		unary("sin", math.Sin),
+		unary("cos", math.Cos),
+		unary("tan", math.Tan),
+		unary("asin", math.Asin),
+		unary("acos", math.Acos),
+		unary("atan", math.Atan),
+		unary("log", math.Log),
+		unary("exp", math.Exp),
+		binary("pow", math.Pow),
+		binary("atan2", math.Atan2),
+		extremum("min", math.Min, -1),
+		extremum("max", math.Max, 1),
+		{name: "random", arity: 0, function: random},
+		{name: "randomInt", arity: 2, function: randomInt},
+		{name: "seed", arity: 1, function: seed},
+		{name: "setRounding", arity: 2, function: setRounding},
+	}
+}
+
+// float returns a number as a float, converting decimals to the nearest
+// one.
+func float(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case decimal.Decimal:
+		return value.Float64(), true
+	}
+	return 0, false
+}
+
+func unary(name string, function func(float64) float64) *NativeFunction {
+	return &NativeFunction{name: name, arity: 1, function: func(interpreter *Interpreter, arguments []any) (any, error) {
+		x, ok := float(arguments[0])
+		if !ok {
+			return nil, fmt.Errorf("%s expects a number.", name)
+		}
+		return function(x), nil
+	}}
+}
+
+// exact is a unary function with a decimal version, so decimals stay exact.
+func exact(name string, function func(float64) float64, decimalFunction func(decimal.Decimal) decimal.Decimal) *NativeFunction {
+	native := unary(name, function)
+	floatFunction := native.function
+	native.function = func(interpreter *Interpreter, arguments []any) (any, error) {
+		if x, ok := arguments[0].(decimal.Decimal); ok {
+			return decimalFunction(x), nil
+		}
+		return floatFunction(interpreter, arguments)
+	}
+	return native
+}
+
+func binary(name string, function func(float64, float64) float64) *NativeFunction {
+	return &NativeFunction{name: name, arity: 2, function: func(interpreter *Interpreter, arguments []any) (any, error) {
+		x, xOk := float(arguments[0])
+		y, yOk := float(arguments[1])
+		if !xOk || !yOk {
+			return nil, fmt.Errorf("%s expects two numbers.", name)
+		}
+		return function(x, y), nil
+	}}
+}
+
+// extremum is min or max, picking the argument whose comparison with the
+// other gives sign. When either is a decimal both are compared as decimals,
+// the way arithmetic mixes them, and the result is a decimal.
+func extremum(name string, function func(float64, float64) float64, sign int) *NativeFunction {
+	native := binary(name, function)
+	floatFunction := native.function
+	native.function = func(interpreter *Interpreter, arguments []any) (any, error) {
+		_, xDecimal := arguments[0].(decimal.Decimal)
+		_, yDecimal := arguments[1].(decimal.Decimal)
+		if !xDecimal && !yDecimal {
+			return floatFunction(interpreter, arguments)
+		}
+		if !isNumber(arguments[0]) || !isNumber(arguments[1]) {
+			return nil, fmt.Errorf("%s expects two numbers.", name)
+		}
+
+		x, err := getDecimal(core.Token{}, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+		y, err := getDecimal(core.Token{}, arguments[1])
+		if err != nil {
+			return nil, err
+		}
+		if y.Cmp(x) == sign {
+			return y, nil
+		}
+		return x, nil
+	}
+	return native
+}
+
+// newRandom creates the generator of an interpreter, seeded from the clock
+// until a script or the host picks a seed.
+func newRandom() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// SetRandomSeed makes the numbers returned by `random` and `randomInt`
+// reproducible, as `seed(n)` does from scripts.
+func (i *Interpreter) SetRandomSeed(seed int64) {
+	i.random.Seed(seed)
+}
+
+// random returns a number in [0, 1).
+func random(interpreter *Interpreter, arguments []any) (any, error) {
+	return interpreter.random.Float64(), nil
+}
+
+// randomInt returns an integer between min and max, both included.
+func randomInt(interpreter *Interpreter, arguments []any) (any, error) {
+	low, lowOk := integer(arguments[0])
+	high, highOk := integer(arguments[1])
+	if !lowOk || !highOk || low > high {
+		return nil, fmt.Errorf("randomInt expects two integers, the first not above the second.")
+	}
+	return float64(low + interpreter.random.Intn(high-low+1)), nil
+}
+
+func seed(interpreter *Interpreter, arguments []any) (any, error) {
+	value, ok := integer(arguments[0])
+	if !ok {
+		return nil, fmt.Errorf("seed expects an integer.")
+	}
+	interpreter.SetRandomSeed(int64(value))
+	return nil, nil
+}
+
+// setRounding implements `setRounding(scale, mode)`, which sets the number of
+// fractional digits decimal divisions keep and how they round, for example
+// `setRounding(2, "half-up")`.
+func setRounding(interpreter *Interpreter, arguments []any) (any, error) {
+	scale, isInteger := integer(arguments[0])
+	name, isString := arguments[1].(string)
+	if !isInteger || !isString || scale < 0 || scale > decimal.MaxScale {
+		return nil, fmt.Errorf("setRounding expects a scale of 0 to %d and a rounding mode.", decimal.MaxScale)
+	}
+
+	mode, err := decimal.ParseRoundingMode(name)
+	if err != nil {
+		return nil, err
+	}
+	interpreter.SetDecimalContext(decimal.Context{Scale: scale, Rounding: mode})
+	return nil, nil
+}
@@ -0,0 +1,166 @@
+package visitor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/decimal"
+)
+
+func TestMathNatives(t *testing.T) {
+	cases := map[string]float64{
+		"sqrt(16)":        4,
+		"pow(2, 10)":      1024,
+		"floor(-2.5)":     -3,
+		"ceil(2.1)":       3,
+		"round(2.5)":      3,
+		"abs(-3)":         3,
+		"min(2, -1)":      -1,
+		"max(2, -1)":      2,
+		"cos(0)":          1,
+		"atan2(1, 1) * 4": math.Pi,
+		"log(E)":          1,
+		"exp(0)":          1,
+	}
+
+	for expression, expected := range cases {
+		value, err := run(t, "", expression)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", expression, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", expression, expected, value)
+		}
+	}
+}
+
+func TestMathNativesOnDecimals(t *testing.T) {
+	// rounding, abs, min and max stay exact, printed the way decimals are
+	exactCases := map[string]string{
+		"floor(-2.5d)":                   "-3",
+		"ceil(2.01d)":                    "3",
+		"round(2.5d)":                    "3",
+		"round(-2.5d)":                   "-3",
+		"floor(19.99d)":                  "19",
+		"abs(-0.10d)":                    "0.10",
+		"min(0.1d, 0.10000001d)":         "0.1",
+		"max(0.1d, 0.10000001d)":         "0.10000001",
+		"min(2, 1.5d)":                   "1.5",
+		"max(2, 1.5d)":                   "2",
+		"floor(12345678901234567890.5d)": "12345678901234567890",
+	}
+	for expression, expected := range exactCases {
+		value, err := run(t, "", expression)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", expression, err.Err)
+		}
+		if _, ok := value.(decimal.Decimal); !ok || Stringify(value) != expected {
+			t.Fatalf("%s: expecting the decimal %s, but got: %v", expression, expected, value)
+		}
+	}
+
+	// the other functions convert to floats
+	floatCases := map[string]float64{
+		"sqrt(16d)":     4,
+		"pow(2d, 10)":   1024,
+		"cos(0d)":       1,
+		"atan2(1d, 1d)": math.Pi / 4,
+		"exp(0d)":       1,
+		"sqrt(2d)":      math.Sqrt2,
+	}
+	for expression, expected := range floatCases {
+		value, err := run(t, "", expression)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", expression, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting the float %v, but got: %v", expression, expected, value)
+		}
+	}
+
+	errorCases := map[string]string{
+		`min(1d, "2")`: "min expects two numbers.",
+		`max(1d, NAN)`: "Cannot convert NAN to a decimal.",
+		`abs(nil)`:     "abs expects a number.",
+	}
+	for expression, expected := range errorCases {
+		_, err := run(t, "", expression)
+		if err.Err == nil || err.Err.Error() != expected {
+			t.Fatalf("%s: expecting error %q, but got: %v", expression, expected, err.Err)
+		}
+	}
+}
+
+func TestMathNativesReportTypeErrorsAtTheCall(t *testing.T) {
+	cases := map[string]string{
+		`sqrt("4");`:       "sqrt expects a number.",
+		`pow(2, nil);`:     "pow expects two numbers.",
+		`randomInt(3, 1);`: "randomInt expects two integers, the first not above the second.",
+		`seed(1.5);`:       "seed expects an integer.",
+	}
+
+	for program, expected := range cases {
+		_, err := run(t, "\n"+program, "nil")
+		if err.Err == nil || err.Err.Error() != expected || err.Line != 2 {
+			t.Fatalf("%s: expecting error %q on line 2, but got: %v on line %d", program, expected, err.Err, err.Line)
+		}
+	}
+}
+
+func TestSeedMakesRandomNumbersReproducible(t *testing.T) {
+	program := "seed(7); var a = random(); var b = randomInt(1, 6);"
+	first, _ := run(t, program, "a + b * 10")
+	second, _ := run(t, program, "a + b * 10")
+	if first != second {
+		t.Fatalf("expecting the same numbers after seeding, but got: %v and %v", first, second)
+	}
+
+	value, _ := run(t, "seed(1);", "randomInt(5, 5)")
+	if value != 5.0 {
+		t.Fatalf("expecting randomInt(5, 5) to be 5, but got: %v", value)
+	}
+}
+
+func TestSpecialNumbersPrintAsConstants(t *testing.T) {
+	cases := map[string]string{"INF": "INF", "-INF": "-INF", "NAN": "NAN", "sqrt(-1)": "NAN", "sqrt(2)": "1.4142135623730951"}
+	for expression, expected := range cases {
+		value, _ := run(t, "", expression)
+		if got := Stringify(value); got != expected {
+			t.Fatalf("%s: expecting %s, but got: %s", expression, expected, got)
+		}
+	}
+}
+
+func TestSetRoundingChangesDecimalDivision(t *testing.T) {
+	cases := map[string]string{
+		"":                             "0.6666666666666667",
+		`setRounding(2, "down");`:      "0.66",
+		`setRounding(2, "up");`:        "0.67",
+		`setRounding(0, "ceiling");`:   "1",
+		`setRounding(2, "floor");`:     "0.66",
+		`setRounding(3, "half-even");`: "0.667",
+		`setRounding(1, "half-down");`: "0.7",
+	}
+
+	for program, expected := range cases {
+		value, err := run(t, program, "2d / 3d")
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", program, err.Err)
+		}
+		if got := Stringify(value); got != expected {
+			t.Fatalf("%s: expecting 2d / 3d to be %s, but got: %s", program, expected, got)
+		}
+	}
+
+	// the new context applies within the statement that set it
+	value, _ := run(t, "", `setRounding(1, "down") ?? 2d / 3d`)
+	if got := Stringify(value); got != "0.6" {
+		t.Fatalf("expecting the rounding to apply right away, but got: %s", got)
+	}
+
+	for _, program := range []string{`setRounding(2, "sideways");`, `setRounding(-1, "up");`, `setRounding(2, 1);`} {
+		if _, err := run(t, program, "nil"); err.Err == nil {
+			t.Fatalf("%s: was expecting an error, but didn't get one", program)
+		}
+	}
+}
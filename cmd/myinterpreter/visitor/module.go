@@ -0,0 +1,246 @@
+package visitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+// ModulePathVariable names the environment variable listing the directories
+// searched for modules that are not found next to the importing file.
+const ModulePathVariable = "LOX_PATH"
+
+const moduleFrameName = "<module>"
+
+// Module is the value of `import "path" as name`. Its properties are the
+// names exported by the module: the variables and functions declared at
+// its top level, except those starting with an underscore.
+type Module struct {
+	path        string
+	environment environment.Environment
+	exports     map[string]bool
+}
+
+func (m *Module) GetProperty(name string) (any, bool) {
+	if !m.exports[name] {
+		return nil, false
+	}
+	return m.environment.Lookup(name)
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("<module %s>", m.path)
+}
+
+// modules is shared by every copy of an Interpreter, like the budget, so
+// each module is run once however it is reached.
+type modules struct {
+	searchPath []string
+	// builtins are the globals every module starts with: the natives and
+	// whatever the embedder defined.
+	builtins map[string]any
+	// loaded maps absolute paths to the modules that ran to completion.
+	loaded map[string]*Module
+	// loading is the chain of modules being run, to report import cycles.
+	loading []string
+}
+
+func createModules() *modules {
+	return &modules{
+		searchPath: filepath.SplitList(os.Getenv(ModulePathVariable)),
+		builtins:   map[string]any{},
+		loaded:     map[string]*Module{},
+	}
+}
+
+// SetModulePath sets the directories searched for modules, which default to
+// the ones listed in LOX_PATH.
+func (i *Interpreter) SetModulePath(directories []string) {
+	i.modules.searchPath = directories
+}
+
+// CurrentScript returns the file of the code being run: a module while it
+// is imported or one of its functions runs, and the script otherwise.
+func (i *Interpreter) CurrentScript() string {
+	if len(i.frames) > 0 {
+		return i.frames[len(i.frames)-1].script
+	}
+	return i.scriptName
+}
+
+// VisitImportStmt runs the module the first time it is imported and binds
+// either the module itself or the names picked from it.
+func (i *Interpreter) VisitImportStmt(stmt core.ImportStmt) (any, core.Error) {
+	module, err := i.importModule(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	if stmt.Names == nil {
+		if err := i.environment.AddVariable(stmt.Alias.Lexeme, module); err != nil {
+			return nil, core.Error{Line: stmt.Alias.Line, Err: err, ExitCode: 70}
+		}
+		return nil, core.Error{}
+	}
+
+	for _, name := range stmt.Names {
+		value, ok := module.GetProperty(name.Name.Lexeme)
+		if !ok {
+			err := fmt.Errorf("Module '%s' has no export '%s'.", module.path, name.Name.Lexeme)
+			return nil, core.Error{Line: name.Name.Line, Err: err, ExitCode: 70}
+		}
+		if err := i.environment.AddVariable(name.Alias.Lexeme, value); err != nil {
+			return nil, core.Error{Line: name.Alias.Line, Err: err, ExitCode: 70}
+		}
+	}
+	return nil, core.Error{}
+}
+
+func (i *Interpreter) importModule(stmt core.ImportStmt) (*Module, core.Error) {
+	line := stmt.Keyword.Line
+	path, err := i.resolveModule(stmt.Path.Literal.(string))
+	if err != nil {
+		return nil, core.Error{Line: line, Err: err, ExitCode: 70}
+	}
+
+	key, _ := filepath.Abs(path)
+	if module, ok := i.modules.loaded[key]; ok {
+		return module, core.Error{}
+	}
+
+	root, _ := filepath.Abs(i.scriptName)
+	chain := append([]string{root}, i.modules.loading...)
+	for index, loading := range chain {
+		if loading == key {
+			cycle := []string{}
+			for _, file := range append(chain[index:], key) {
+				cycle = append(cycle, filepath.Base(file))
+			}
+			err := fmt.Errorf("Import cycle: %s.", strings.Join(cycle, " -> "))
+			return nil, core.Error{Line: line, Err: err, ExitCode: 70}
+		}
+	}
+
+	statements, exports, readErr := readModule(path)
+	if readErr.Err != nil {
+		readErr.Err = fmt.Errorf("%s:%d: %w", path, readErr.Line, readErr.Err)
+		readErr.Line = line
+		return nil, readErr
+	}
+
+	i.modules.loading = append(i.modules.loading, key)
+	defer func() { i.modules.loading = i.modules.loading[:len(i.modules.loading)-1] }()
+
+	module := &Module{path: path, environment: i.moduleEnvironment(), exports: exports}
+	if err := i.runModule(module, statements, line); err.Err != nil {
+		return nil, err
+	}
+
+	i.modules.loaded[key] = module
+	return module, core.Error{}
+}
+
+// resolveModule looks for a module next to the importing file, then in each
+// directory of the search path, skipping the files the interpreter's
+// FileAccess doesn't allow. When only those were left, the error is the
+// access error.
+func (i *Interpreter) resolveModule(name string) (string, error) {
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = nil
+		directories := append([]string{filepath.Dir(i.CurrentScript())}, i.modules.searchPath...)
+		for _, directory := range directories {
+			candidates = append(candidates, filepath.Join(directory, name))
+		}
+	}
+
+	var denied error
+	for _, candidate := range candidates {
+		absolute, err := filepath.Abs(candidate)
+		if err != nil {
+			continue
+		}
+		if _, err := i.fileAccess.resolve(absolute); err != nil {
+			denied = err
+			continue
+		}
+		if isFile(candidate) {
+			return candidate, nil
+		}
+	}
+
+	if denied != nil {
+		return "", denied
+	}
+	return "", fmt.Errorf("Module '%s' not found.", name)
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// readModule parses a module and lists the names it exports. Errors keep
+// the line they have in the module.
+func readModule(path string) ([]core.Statement, map[string]bool, core.Error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, core.Error{Err: fmt.Errorf("Error reading module: %w", err), ExitCode: 70}
+	}
+
+	tokens, errs := scanner.ScanFile(source)
+	if len(errs) != 0 {
+		return nil, nil, errs[0]
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		return nil, nil, *parseErr
+	}
+
+	exports := map[string]bool{}
+	for _, statement := range statements {
+		name := ""
+		switch node := statement.(type) {
+		case core.VarStmt:
+			name = node.Name.Lexeme
+		case core.FunctionStmt:
+			name = node.Name.Lexeme
+		}
+		if name != "" && !strings.HasPrefix(name, "_") {
+			exports[name] = true
+		}
+	}
+	return statements, exports, core.Error{}
+}
+
+// moduleEnvironment creates the globals of a module, which see the builtins
+// but nothing the importing scripts declared.
+func (i *Interpreter) moduleEnvironment() environment.Environment {
+	globals := environment.CreateEnvironment()
+	defineNatives(&globals)
+	for name, value := range i.modules.builtins {
+		globals.AddVariable(name, value)
+	}
+	globals.SetAllocator(i.budget)
+	return globals
+}
+
+// runModule runs the top level of a module inside a frame of its own, so
+// errors are traced back through the import.
+func (i *Interpreter) runModule(module *Module, statements []core.Statement, line int) core.Error {
+	depth := len(i.frames)
+	i.frames = append(i.frames, callFrame{function: moduleFrameName, script: module.path, callLine: line, caller: i.environment})
+	defer func() { i.frames = i.frames[:depth] }()
+
+	_, err := i.executeBlock(statements, module.environment)
+	if err.Err != nil && err.Trace == nil {
+		err.Trace = i.stackTrace(err.Line)
+	}
+	return err
+}
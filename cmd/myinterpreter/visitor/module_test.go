@@ -0,0 +1,225 @@
+package visitor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/scanner"
+)
+
+// runFiles writes files to a temporary directory and runs main.lox from it,
+// returning what it printed.
+func runFiles(t *testing.T, files map[string]string) (string, core.Error) {
+	t.Helper()
+	return runFilesWithAccess(t, FileAccess{}, files)
+}
+
+// runFilesWithAccess is runFiles restricted by access, whose Root is relative
+// to the temporary directory.
+func runFilesWithAccess(t *testing.T, access FileAccess, files map[string]string) (string, core.Error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if access.Root != "" {
+		access.Root = filepath.Join(dir, access.Root)
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tokens, errs := scanner.ScanFile([]byte(files["main.lox"]))
+	if len(errs) != 0 {
+		t.Fatalf("was not expecting any scan errors, but got: %v", errs[0].Err)
+	}
+	statements, parseErr := parser.Parse(tokens)
+	if parseErr != nil {
+		t.Fatalf("was not expecting any parse errors, but got: %v", parseErr.Err)
+	}
+
+	var output bytes.Buffer
+	interpreter := CreateInterpreter()
+	interpreter.SetScriptName(filepath.Join(dir, "main.lox"))
+	interpreter.SetModulePath([]string{filepath.Join(dir, "path")})
+	interpreter.SetFileAccess(access)
+	interpreter.SetOutput(&output)
+	for _, statement := range statements {
+		if _, err := interpreter.Interpret(statement); err.Err != nil {
+			return output.String(), err
+		}
+	}
+	return output.String(), core.Error{}
+}
+
+func TestImportModule(t *testing.T) {
+	output, err := runFiles(t, map[string]string{
+		"main.lox": `
+import "lib/math.lox" as math;
+import { square, offset as shift } from "lib/math.lox";
+print math.square(3);
+print square(4) + shift;
+math.bump();
+print math.offset;
+`,
+		"lib/math.lox": `
+print "loading math";
+var offset = 10;
+var _hidden = 1;
+fun square(x) { return x * x + _hidden - 1; }
+fun bump() { offset = offset + 1; }
+`,
+	})
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+
+	expected := "loading math\n9\n26\n11\n"
+	if output != expected {
+		t.Fatalf("expecting output %q, but got: %q", expected, output)
+	}
+}
+
+func TestImportUsesSearchPath(t *testing.T) {
+	output, err := runFiles(t, map[string]string{
+		"main.lox":       `import { greet } from "greet.lox"; greet();`,
+		"path/greet.lox": `import { name } from "name.lox"; fun greet() { print "hi " + name; }`,
+		"path/name.lox":  `var name = "there";`,
+		"name.lox":       `var name = "wrong";`,
+	})
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if output != "hi there\n" {
+		t.Fatalf("expecting the module next to greet.lox to be found, but got: %q", output)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+		exitCode int
+	}{
+		{
+			"missing module",
+			map[string]string{"main.lox": `import "nope.lox" as nope;`},
+			"Module 'nope.lox' not found.",
+			70,
+		},
+		{
+			"private name",
+			map[string]string{"main.lox": `import { _secret } from "lib.lox";`, "lib.lox": `var _secret = 1;`},
+			"has no export '_secret'.",
+			70,
+		},
+		{
+			"natives are not exported",
+			map[string]string{"main.lox": `import "lib.lox" as lib; print lib.Error;`, "lib.lox": `var x = 1;`},
+			"Undefined property 'Error'.",
+			70,
+		},
+		{
+			"module globals are separate",
+			map[string]string{"main.lox": `var secret = 1; import "lib.lox" as lib;`, "lib.lox": `print secret;`},
+			"Undefined variable 'secret'.",
+			70,
+		},
+		{
+			"cycle",
+			map[string]string{"main.lox": `import "a.lox" as a;`, "a.lox": `import "b.lox" as b;`, "b.lox": `import "a.lox" as a;`},
+			"Import cycle: a.lox -> b.lox -> a.lox.",
+			70,
+		},
+		{
+			"cycle through the script",
+			map[string]string{"main.lox": `import "a.lox" as a;`, "a.lox": `import "main.lox" as main;`},
+			"Import cycle: main.lox -> a.lox -> main.lox.",
+			70,
+		},
+		{
+			"parse error in module",
+			map[string]string{"main.lox": "\nimport \"a.lox\" as a;", "a.lox": "var = 1;"},
+			"a.lox:1: Expect variable name.",
+			65,
+		},
+	}
+
+	for _, c := range cases {
+		_, err := runFiles(t, c.files)
+		if err.Err == nil || !strings.Contains(err.Err.Error(), c.expected) || err.ExitCode != c.exitCode {
+			t.Fatalf("%s: expecting error %q with exit code %d, but got: %v (%d)", c.name, c.expected, c.exitCode, err.Err, err.ExitCode)
+		}
+	}
+}
+
+func TestImportsFollowFileAccess(t *testing.T) {
+	files := map[string]string{"main.lox": `import { x } from "lib.lox"; print x;`, "lib.lox": `var x = 1;`}
+	if _, err := runFilesWithAccess(t, FileAccess{Disabled: true}, files); !errors.Is(err.Err, ErrFileAccessDenied) || err.ExitCode != 70 {
+		t.Fatalf("expecting imports to be denied when file access is disabled, but got: %v (%d)", err.Err, err.ExitCode)
+	}
+
+	output, err := runFilesWithAccess(t, FileAccess{Root: "."}, files)
+	if err.Err != nil || output != "1\n" {
+		t.Fatalf("expecting modules under the root to be imported, but got: %q (%v)", output, err.Err)
+	}
+
+	outside := filepath.Join(t.TempDir(), "outside.lox")
+	if err := os.WriteFile(outside, []byte(`var x = 2;`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files = map[string]string{"main.lox": `import { x } from "` + filepath.ToSlash(outside) + `"; print x;`}
+	_, err = runFilesWithAccess(t, FileAccess{Root: "."}, files)
+	if !errors.Is(err.Err, ErrFileAccessDenied) || !strings.Contains(err.Err.Error(), "outside.lox") {
+		t.Fatalf("expecting imports outside the root to be denied, but got: %v", err.Err)
+	}
+}
+
+func TestModuleRunsOnce(t *testing.T) {
+	output, err := runFiles(t, map[string]string{
+		"main.lox":    `import "a.lox" as a; import "b.lox" as b; print a.count + b.count;`,
+		"a.lox":       `import "counter.lox" as counter; var count = counter.n;`,
+		"b.lox":       `import "counter.lox" as counter; var count = counter.n;`,
+		"counter.lox": `print "counted"; var n = 1;`,
+	})
+	if err.Err != nil {
+		t.Fatalf("was not expecting any errors, but got: %v", err.Err)
+	}
+	if output != "counted\n2\n" {
+		t.Fatalf("expecting the shared module to run once, but got: %q", output)
+	}
+}
+
+func TestModuleErrorsAreTracedThroughImports(t *testing.T) {
+	_, err := runFiles(t, map[string]string{
+		"main.lox": "import \"lib.lox\" as lib;\nlib.fail();",
+		"lib.lox":  "fun fail() {\n  return 1 + nil;\n}",
+	})
+	if err.Err == nil {
+		t.Fatal("was expecting a runtime error, but didn't get one")
+	}
+
+	scripts := []string{}
+	for _, frame := range err.Trace {
+		scripts = append(scripts, filepath.Base(frame.Script)+":"+frame.Function)
+	}
+	expected := []string{"lib.lox:fail", "main.lox:<script>"}
+	if !reflect.DeepEqual(scripts, expected) {
+		t.Fatalf("expecting frames %v, but got: %v", expected, scripts)
+	}
+	if err.Line != 2 {
+		t.Fatalf("expecting the error at line 2, but got: %d", err.Line)
+	}
+}
@@ -0,0 +1,149 @@
+package visitor
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/core"
+	"github.com/codecrafters-io/interpreter-starter-go/cmd/myinterpreter/environment"
+)
+
+// NativeFunction is a Callable implemented in Go. Errors it returns are
+// reported as runtime errors at the line of the call, except for a
+// core.Error, which is passed through as is so an error raised by a Lox
+// function the native called keeps its line and trace.
+type NativeFunction struct {
+	name  string
+	arity int
+	// optional is how many of the last arguments calls may leave out. They
+	// are passed to function as nil.
+	optional int
+	function func(interpreter *Interpreter, arguments []any) (any, error)
+}
+
+// CreateNativeFunction wraps a Go function so embedders can hand it to
+// scripts with Interpreter.Define.
+func CreateNativeFunction(name string, arity int, function func(interpreter *Interpreter, arguments []any) (any, error)) *NativeFunction {
+	return &NativeFunction{name: name, arity: arity, function: function}
+}
+
+func (n *NativeFunction) Name() string {
+	return n.name
+}
+
+func (n *NativeFunction) Arity() int {
+	return n.arity
+}
+
+// checkArguments checks the number of arguments a call passes to function,
+// filling in nil for the optional arguments of a native that were left out.
+func checkArguments(function Callable, arguments []any) ([]any, error) {
+	arity := function.Arity()
+	fewest := arity
+	if native, ok := function.(*NativeFunction); ok {
+		fewest -= native.optional
+	}
+
+	if len(arguments) < fewest || len(arguments) > arity {
+		if fewest == arity {
+			return nil, fmt.Errorf("Expected %d arguments but got %d.", arity, len(arguments))
+		}
+		return nil, fmt.Errorf("Expected %d to %d arguments but got %d.", fewest, arity, len(arguments))
+	}
+
+	for len(arguments) < arity {
+		arguments = append(arguments, nil)
+	}
+	return arguments, nil
+}
+
+func (n *NativeFunction) Call(interpreter *Interpreter, arguments []any) (any, core.Error) {
+	value, err := n.function(interpreter, arguments)
+	if loxErr, ok := err.(core.Error); ok {
+		return nil, loxErr
+	}
+	if err != nil {
+		return nil, core.Error{Err: err, ExitCode: 70}
+	}
+
+	return value, core.Error{}
+}
+
+func (n *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+func natives() []*NativeFunction {
+	natives := []*NativeFunction{
+		{name: "Error", arity: 1, function: newError},
+		{name: "keys", arity: 1, function: keys},
+		{name: "get", arity: 2, function: get},
+	}
+	natives = append(natives, stringNatives()...)
+	natives = append(natives, mathNatives()...)
+	return append(natives, fileNatives()...)
+}
+
+// constant is a global value every interpreter defines next to the natives.
+type constant struct {
+	name  string
+	value any
+}
+
+func constants() []constant {
+	return append(mathConstants(), constant{name: "json", value: jsonNamespace()})
+}
+
+// Namespace groups natives under one global, as in `json.parse(text)`.
+type Namespace struct {
+	name    string
+	members map[string]any
+}
+
+func (n *Namespace) GetProperty(name string) (any, bool) {
+	value, ok := n.members[name]
+	return value, ok
+}
+
+func (n *Namespace) String() string {
+	return fmt.Sprintf("<native %s>", n.name)
+}
+
+func defineNatives(env *environment.Environment) {
+	for _, native := range natives() {
+		env.AddVariable(native.name, native)
+	}
+	for _, constant := range constants() {
+		env.AddVariable(constant.name, constant.value)
+	}
+}
+
+// NativeNames lists the globals every interpreter defines before running a
+// script, for tools that resolve names without running it.
+func NativeNames() []string {
+	names := []string{}
+	for _, native := range natives() {
+		names = append(names, native.name)
+	}
+	return append(names, NativeConstantNames()...)
+}
+
+// NativeConstantNames lists the globals of NativeNames that are not
+// functions, such as PI and json.
+func NativeConstantNames() []string {
+	names := []string{}
+	for _, constant := range constants() {
+		names = append(names, constant.name)
+	}
+	return names
+}
+
+// newError implements `Error(message)`, which builds an error object for
+// `throw`. Its line is filled in by the throw statement.
+func newError(interpreter *Interpreter, arguments []any) (any, error) {
+	message := Stringify(arguments[0])
+	if err := interpreter.budget.reserve(errorObjectSize + len(message)); err != nil {
+		return nil, err
+	}
+
+	return &LoxError{Message: message}, nil
+}
@@ -108,6 +108,146 @@ func (p PrinterVisitor) VisitAssignExpr(expr core.Assign) (any, core.Error) {
 	return str, core.Error{}
 }
 
+func (p PrinterVisitor) VisitCompoundAssignExpr(expr core.CompoundAssign) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitCompoundAssignExpr(expr)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitIncrementExpr(expr core.Increment) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitIncrementExpr(expr)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitConditionalExpr(expr core.Conditional) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitConditionalExpr(expr)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitLogicalExpr(expr core.Logical) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitLogicalExpr(expr)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitCallExpr(expr core.Call) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitCallExpr(expr)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitFunctionStmt(stmt core.FunctionStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitFunctionStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitReturnStmt(stmt core.ReturnStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitReturnStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitGetExpr(expr core.Get) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitGetExpr(expr)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitThrowStmt(stmt core.ThrowStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitThrowStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitTryStmt(stmt core.TryStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitTryStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitIfStmt(stmt core.IfStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitIfStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitWhileStmt(stmt core.WhileStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitWhileStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitForStmt(stmt core.ForStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitForStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
+func (p PrinterVisitor) VisitImportStmt(stmt core.ImportStmt) (any, core.Error) {
+	str, err := p.stringifyVisitor.VisitImportStmt(stmt)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	fmt.Println(str)
+	return str, core.Error{}
+}
+
 // VisitBlockStmt implements core.StatementVisitor.
 func (p PrinterVisitor) VisitBlockStmt(stmt core.BlockStmt) (any, core.Error) {
 	panic("unimplemented")
@@ -0,0 +1,87 @@
+package visitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Record is a set of named fields, such as a JSON object. Scripts read
+// fields as properties, `record.name`, or by a computed key with
+// `get(record, key)`, and list them with `keys(record)`.
+// Fields keep the order they were added in.
+type Record struct {
+	keys   []string
+	fields map[string]any
+	// bytes caches size until a field is set.
+	bytes int
+}
+
+func CreateRecord() *Record {
+	return &Record{fields: map[string]any{}}
+}
+
+// Set adds a field, or replaces its value when it exists.
+func (r *Record) Set(key string, value any) {
+	if _, exists := r.fields[key]; !exists {
+		r.keys = append(r.keys, key)
+	}
+	r.fields[key] = value
+	r.bytes = 0
+}
+
+// size approximates the memory taken by the record and its fields.
+func (r *Record) size() int {
+	if r.bytes == 0 {
+		for _, key := range r.keys {
+			r.bytes += listEntrySize + len(key) + sizeOf(r.fields[key])
+		}
+	}
+	return r.bytes
+}
+
+func (r *Record) Keys() []string {
+	return r.keys
+}
+
+func (r *Record) GetProperty(name string) (any, bool) {
+	value, ok := r.fields[name]
+	return value, ok
+}
+
+// String shows the fields the way assertion failures do, with strings in
+// quotes.
+func (r *Record) String() string {
+	fields := make([]string, len(r.keys))
+	for index, key := range r.keys {
+		fields[index] = fmt.Sprintf("%q: %s", key, quote(r.fields[key]))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// keys implements `keys(record)`.
+func keys(interpreter *Interpreter, arguments []any) (any, error) {
+	record, ok := arguments[0].(*Record)
+	if !ok {
+		return nil, fmt.Errorf("keys expects a record.")
+	}
+	if err := interpreter.budget.reserve(len(record.keys) * listEntrySize); err != nil {
+		return nil, err
+	}
+
+	elements := make([]any, len(record.keys))
+	for index, key := range record.keys {
+		elements[index] = key
+	}
+	return &List{Elements: elements}, nil
+}
+
+// get implements `get(record, key)`, which is nil when the record has no
+// field key.
+func get(interpreter *Interpreter, arguments []any) (any, error) {
+	record, isRecord := arguments[0].(*Record)
+	key, isString := arguments[1].(string)
+	if !isRecord || !isString {
+		return nil, fmt.Errorf("get expects a record and a string key.")
+	}
+	return record.fields[key], nil
+}
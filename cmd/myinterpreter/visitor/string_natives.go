@@ -0,0 +1,237 @@
+package visitor
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stringNatives work on Unicode code points: lengths and indices count
+// characters, not bytes. Every string they build is checked against the
+// memory quota before it is built.
+func stringNatives() []*NativeFunction {
+	return []*NativeFunction{
+		{name: "len", arity: 1, function: length},
+		{name: "substring", arity: 3, function: substring},
+		{name: "indexOf", arity: 2, function: indexOf},
+		{name: "split", arity: 2, function: split},
+		{name: "join", arity: 2, function: join},
+		{name: "upper", arity: 1, function: upper},
+		{name: "lower", arity: 1, function: lower},
+		{name: "trim", arity: 1, function: trim},
+		{name: "replace", arity: 3, function: replace},
+		{name: "startsWith", arity: 2, function: startsWith},
+		{name: "contains", arity: 2, function: contains},
+		{name: "repeat", arity: 2, function: repeat},
+		{name: "charAt", arity: 2, function: charAt},
+		{name: "ord", arity: 1, function: ord},
+		{name: "chr", arity: 1, function: chr},
+	}
+}
+
+// strs returns the arguments as strings, or false if any of them isn't one.
+func strs(arguments ...any) ([]string, bool) {
+	values := make([]string, len(arguments))
+	for index, argument := range arguments {
+		value, ok := argument.(string)
+		if !ok {
+			return nil, false
+		}
+		values[index] = value
+	}
+	return values, true
+}
+
+// newString checks a string built by a native against the memory quota.
+func newString(interpreter *Interpreter, value string) (any, error) {
+	if err := interpreter.budget.reserve(len(value)); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// length implements `len`, which also counts the elements of a list.
+func length(interpreter *Interpreter, arguments []any) (any, error) {
+	switch value := arguments[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(value)), nil
+	case *List:
+		return float64(len(value.Elements)), nil
+	}
+	return nil, fmt.Errorf("len expects a string or a list.")
+}
+
+// substring returns the characters from start up to, but not including, end.
+func substring(interpreter *Interpreter, arguments []any) (any, error) {
+	str, isString := arguments[0].(string)
+	start, startOk := integer(arguments[1])
+	end, endOk := integer(arguments[2])
+	if !isString || !startOk || !endOk {
+		return nil, fmt.Errorf("substring expects a string and two integer indices.")
+	}
+
+	runes := []rune(str)
+	if start < 0 || end > len(runes) || start > end {
+		return nil, fmt.Errorf("Substring %d to %d out of range for a string of length %d.", start, end, len(runes))
+	}
+	return newString(interpreter, string(runes[start:end]))
+}
+
+// indexOf returns the index of the first occurrence of a string, or -1.
+func indexOf(interpreter *Interpreter, arguments []any) (any, error) {
+	values, ok := strs(arguments...)
+	if !ok {
+		return nil, fmt.Errorf("indexOf expects two strings.")
+	}
+
+	index := strings.Index(values[0], values[1])
+	if index < 0 {
+		return float64(-1), nil
+	}
+	return float64(utf8.RuneCountInString(values[0][:index])), nil
+}
+
+// split cuts a string around every separator, or into its characters when
+// the separator is empty.
+func split(interpreter *Interpreter, arguments []any) (any, error) {
+	values, ok := strs(arguments...)
+	if !ok {
+		return nil, fmt.Errorf("split expects two strings.")
+	}
+
+	parts := strings.Split(values[0], values[1])
+	if err := interpreter.budget.reserve(len(values[0]) + len(parts)*listEntrySize); err != nil {
+		return nil, err
+	}
+
+	elements := make([]any, len(parts))
+	for index, part := range parts {
+		elements[index] = part
+	}
+	return &List{Elements: elements}, nil
+}
+
+// join puts the elements of a list together, separated by a string. Elements
+// that aren't strings are formatted the way `print` shows them.
+func join(interpreter *Interpreter, arguments []any) (any, error) {
+	list, isList := arguments[0].(*List)
+	separator, isString := arguments[1].(string)
+	if !isList || !isString {
+		return nil, fmt.Errorf("join expects a list and a string.")
+	}
+
+	parts := make([]string, len(list.Elements))
+	for index, element := range list.Elements {
+		parts[index] = Stringify(element)
+	}
+	return newString(interpreter, strings.Join(parts, separator))
+}
+
+func upper(interpreter *Interpreter, arguments []any) (any, error) {
+	str, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("upper expects a string.")
+	}
+	return newString(interpreter, strings.ToUpper(str))
+}
+
+func lower(interpreter *Interpreter, arguments []any) (any, error) {
+	str, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("lower expects a string.")
+	}
+	return newString(interpreter, strings.ToLower(str))
+}
+
+// trim removes white space from both ends of a string.
+func trim(interpreter *Interpreter, arguments []any) (any, error) {
+	str, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("trim expects a string.")
+	}
+	return strings.TrimFunc(str, unicode.IsSpace), nil
+}
+
+// replace replaces every occurrence of a string with another.
+func replace(interpreter *Interpreter, arguments []any) (any, error) {
+	values, ok := strs(arguments...)
+	if !ok {
+		return nil, fmt.Errorf("replace expects three strings.")
+	}
+
+	count := strings.Count(values[0], values[1])
+	if err := interpreter.budget.reserve(len(values[0]) + count*(len(values[2])-len(values[1]))); err != nil {
+		return nil, err
+	}
+	return strings.ReplaceAll(values[0], values[1], values[2]), nil
+}
+
+func startsWith(interpreter *Interpreter, arguments []any) (any, error) {
+	values, ok := strs(arguments...)
+	if !ok {
+		return nil, fmt.Errorf("startsWith expects two strings.")
+	}
+	return strings.HasPrefix(values[0], values[1]), nil
+}
+
+func contains(interpreter *Interpreter, arguments []any) (any, error) {
+	values, ok := strs(arguments...)
+	if !ok {
+		return nil, fmt.Errorf("contains expects two strings.")
+	}
+	return strings.Contains(values[0], values[1]), nil
+}
+
+// repeat concatenates count copies of a string. The size is checked against
+// the memory quota first, so a huge count fails without building anything.
+func repeat(interpreter *Interpreter, arguments []any) (any, error) {
+	str, isString := arguments[0].(string)
+	count, isInteger := integer(arguments[1])
+	if !isString || !isInteger || count < 0 {
+		return nil, fmt.Errorf("repeat expects a string and a non-negative integer.")
+	}
+
+	if len(str) > 0 && count > maxAllocation/len(str) {
+		return nil, ErrMemoryLimitExceeded
+	}
+	if err := interpreter.budget.reserve(len(str) * count); err != nil {
+		return nil, err
+	}
+	return strings.Repeat(str, count), nil
+}
+
+// charAt returns the character at an index as a string of its own.
+func charAt(interpreter *Interpreter, arguments []any) (any, error) {
+	str, isString := arguments[0].(string)
+	index, isInteger := integer(arguments[1])
+	if !isString || !isInteger {
+		return nil, fmt.Errorf("charAt expects a string and an integer index.")
+	}
+
+	runes := []rune(str)
+	if index < 0 || index >= len(runes) {
+		return nil, fmt.Errorf("Index %d out of range for a string of length %d.", index, len(runes))
+	}
+	return newString(interpreter, string(runes[index]))
+}
+
+// ord returns the code point of a one-character string.
+func ord(interpreter *Interpreter, arguments []any) (any, error) {
+	str, ok := arguments[0].(string)
+	if !ok || utf8.RuneCountInString(str) != 1 {
+		return nil, fmt.Errorf("ord expects a string of one character.")
+	}
+
+	r, _ := utf8.DecodeRuneInString(str)
+	return float64(r), nil
+}
+
+// chr returns the one-character string of a code point.
+func chr(interpreter *Interpreter, arguments []any) (any, error) {
+	code, ok := integer(arguments[0])
+	if !ok || code < 0 || code > unicode.MaxRune || !utf8.ValidRune(rune(code)) {
+		return nil, fmt.Errorf("chr expects a valid code point.")
+	}
+	return newString(interpreter, string(rune(code)))
+}
@@ -0,0 +1,85 @@
+package visitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStringNatives(t *testing.T) {
+	cases := map[string]any{
+		`len("héllo")`:                    5.0,
+		`substring("naïve café", 6, 10)`:  "café",
+		`indexOf("naïve café", "café")`:   6.0,
+		`indexOf("abc", "z")`:             -1.0,
+		`join(split("a,b,,c", ","), "-")`: "a-b--c",
+		`split("añb", "").length`:         3.0,
+		`split("a b", " ").get(1)`:        "b",
+		`upper("éa")`:                     "ÉA",
+		`lower("ÀB")`:                     "àb",
+		"trim(\"  \t hi \n\")":            "hi",
+		`replace("a-b-c", "-", "+")`:      "a+b+c",
+		`startsWith("lox", "lo")`:         true,
+		`contains("lox", "ox")`:           true,
+		`contains("lox", "x!")`:           false,
+		`repeat("ab", 3)`:                 "ababab",
+		`charAt("日本語", 1)`:                "本",
+		`ord("é")`:                        233.0,
+		`chr(26085)`:                      "日",
+	}
+
+	for expression, expected := range cases {
+		value, err := run(t, "", expression)
+		if err.Err != nil {
+			t.Fatalf("%s: was not expecting any errors, but got: %v", expression, err.Err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %v, but got: %v", expression, expected, value)
+		}
+	}
+}
+
+func TestStringNativesReportTypeErrorsAtTheCall(t *testing.T) {
+	cases := map[string]string{
+		"upper(1);":                    "upper expects a string.",
+		"substring(\"abc\", 1.5, 2);":  "substring expects a string and two integer indices.",
+		"substring(\"abc\", 2, 5);":    "Substring 2 to 5 out of range for a string of length 3.",
+		"charAt(\"abc\", -1);":         "Index -1 out of range for a string of length 3.",
+		"ord(\"ab\");":                 "ord expects a string of one character.",
+		"chr(-1);":                     "chr expects a valid code point.",
+		"join(\"abc\", \",\");":        "join expects a list and a string.",
+		"split(\"abc\", \"\").get(3);": "Index 3 out of range for a list of length 3.",
+	}
+
+	for program, expected := range cases {
+		_, err := run(t, "\n"+program, "nil")
+		if err.Err == nil || err.Err.Error() != expected {
+			t.Fatalf("%s: expecting error %q, but got: %v", program, expected, err.Err)
+		}
+		if err.Line != 2 {
+			t.Fatalf("%s: expecting the error on line 2, but got: %d", program, err.Line)
+		}
+	}
+}
+
+func TestRepeatRespectsMemoryLimit(t *testing.T) {
+	err := runLimited(t, context.Background(), Limits{MaxMemory: 1 << 20}, `var s = repeat("abc", 1000000);`)
+	if !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting a memory limit error, but got: %v", err.Err)
+	}
+
+	err = runLimited(t, context.Background(), Limits{}, `var s = repeat("abc", 1000000000000000);`)
+	if !errors.Is(err, ErrMemoryLimitExceeded) {
+		t.Fatalf("expecting oversized strings to be refused, but got: %v", err.Err)
+	}
+}
+
+func TestListsPrintWithQuotedStrings(t *testing.T) {
+	value, _ := run(t, "", `split("a,1", ",")`)
+	if got := Stringify(value); got != `["a", "1"]` {
+		t.Fatalf("expecting the list to print as [\"a\", \"1\"], but got: %s", got)
+	}
+	if Stringify(&List{Elements: []any{1.0, nil}}) != "[1, nil]" {
+		t.Fatal("expecting numbers and nil to print the way print shows them")
+	}
+}
@@ -40,12 +40,12 @@ func (p StringifyVisitor) VisitPrintStmt(stmt core.PrintStmt) (any, core.Error)
 }
 
 func (p StringifyVisitor) VisitBinaryExpr(expr core.Binary) (any, core.Error) {
-	right, err := expr.Right.Accept(p)
+	left, err := expr.Left.Accept(p)
 	if err.Err != nil {
 		return nil, err
 	}
 
-	left, err := expr.Left.Accept(p)
+	right, err := expr.Right.Accept(p)
 	if err.Err != nil {
 		return nil, err
 	}
@@ -100,21 +100,188 @@ func (p StringifyVisitor) VisitUnaryExpr(expr core.Unary) (any, core.Error) {
 }
 
 func (p StringifyVisitor) VisitVariableExpr(expr core.Variable) (any, core.Error) {
-	value, err := expr.Accept(p)
+	return expr.Name.Lexeme, core.Error{}
+}
+
+func (p StringifyVisitor) VisitAssignExpr(expr core.Assign) (any, core.Error) {
+	value, err := expr.Value.Accept(p)
 	if err.Err != nil {
 		return nil, err
 	}
 
-	str := fmt.Sprintf("(%s %v)", expr.Name.Lexeme, value)
+	str := fmt.Sprintf("(= %s %v)", expr.Name.Lexeme, value)
 	return str, core.Error{}
 }
 
-func (p StringifyVisitor) VisitAssignExpr(expr core.Assign) (any, core.Error) {
-	value, err := expr.Accept(p)
+func (p StringifyVisitor) VisitCompoundAssignExpr(expr core.CompoundAssign) (any, core.Error) {
+	value, err := expr.Value.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(%s %s %v)", expr.Operator.Lexeme, expr.Name.Lexeme, value)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitIncrementExpr(expr core.Increment) (any, core.Error) {
+	if expr.Prefix {
+		return fmt.Sprintf("(%s %s)", expr.Operator.Lexeme, expr.Name.Lexeme), core.Error{}
+	}
+
+	return fmt.Sprintf("(%s %s)", expr.Name.Lexeme, expr.Operator.Lexeme), core.Error{}
+}
+
+func (p StringifyVisitor) VisitConditionalExpr(expr core.Conditional) (any, core.Error) {
+	condition, err := expr.Condition.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	thenBranch, err := expr.Then.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	elseBranch, err := expr.Else.Accept(p)
 	if err.Err != nil {
 		return nil, err
 	}
 
-	str := fmt.Sprintf("(%s %v)", expr.Name.Lexeme, value)
+	str := fmt.Sprintf("(?: %v %v %v)", condition, thenBranch, elseBranch)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitLogicalExpr(expr core.Logical) (any, core.Error) {
+	left, err := expr.Left.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	right, err := expr.Right.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(%s %v %v)", expr.Operator.Lexeme, left, right)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitCallExpr(expr core.Call) (any, core.Error) {
+	callee, err := expr.Callee.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(call %v", callee)
+	for _, argument := range expr.Arguments {
+		value, err := argument.Accept(p)
+		if err.Err != nil {
+			return nil, err
+		}
+		str += fmt.Sprintf(" %v", value)
+	}
+
+	return str + ")", core.Error{}
+}
+
+func (p StringifyVisitor) VisitFunctionStmt(stmt core.FunctionStmt) (any, core.Error) {
+	params := []string{}
+	for _, param := range stmt.Params {
+		params = append(params, param.Lexeme)
+	}
+
+	str := fmt.Sprintf("(fun %s (%s))", stmt.Name.Lexeme, strings.Join(params, " "))
 	return str, core.Error{}
 }
+
+func (p StringifyVisitor) VisitReturnStmt(stmt core.ReturnStmt) (any, core.Error) {
+	if stmt.Value == nil {
+		return "(return)", core.Error{}
+	}
+
+	value, err := stmt.Value.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(return %v)", value)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitGetExpr(expr core.Get) (any, core.Error) {
+	object, err := expr.Object.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(. %v %s)", object, expr.Name.Lexeme)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitThrowStmt(stmt core.ThrowStmt) (any, core.Error) {
+	value, err := stmt.Value.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(throw %v)", value)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitTryStmt(stmt core.TryStmt) (any, core.Error) {
+	str := "(try"
+	if stmt.HasCatch {
+		str += fmt.Sprintf(" (catch %s)", stmt.CatchName.Lexeme)
+	}
+	if stmt.FinallyBody != nil {
+		str += " (finally)"
+	}
+
+	return str + ")", core.Error{}
+}
+
+func (p StringifyVisitor) VisitIfStmt(stmt core.IfStmt) (any, core.Error) {
+	condition, err := stmt.Condition.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(if %v)", condition)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitWhileStmt(stmt core.WhileStmt) (any, core.Error) {
+	condition, err := stmt.Condition.Accept(p)
+	if err.Err != nil {
+		return nil, err
+	}
+
+	str := fmt.Sprintf("(while %v)", condition)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitForStmt(stmt core.ForStmt) (any, core.Error) {
+	condition := any("")
+	if stmt.Condition != nil {
+		var err core.Error
+		condition, err = stmt.Condition.Accept(p)
+		if err.Err != nil {
+			return nil, err
+		}
+	}
+
+	str := fmt.Sprintf("(for %v)", condition)
+	return str, core.Error{}
+}
+
+func (p StringifyVisitor) VisitImportStmt(stmt core.ImportStmt) (any, core.Error) {
+	if stmt.Names == nil {
+		return fmt.Sprintf("(import %s as %s)", stmt.Path.Lexeme, stmt.Alias.Lexeme), core.Error{}
+	}
+
+	names := []string{}
+	for _, name := range stmt.Names {
+		names = append(names, name.Alias.Lexeme)
+	}
+	return fmt.Sprintf("(import %s %s)", stmt.Path.Lexeme, strings.Join(names, " ")), core.Error{}
+}